@@ -0,0 +1,128 @@
+package bincmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/lasthyphen/ecctools/pkg/application"
+	"github.com/lasthyphen/ecctools/pkg/binaries"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var app *application.GoGoTools
+
+func NewCmd(injectedApp *application.GoGoTools) *cobra.Command {
+	app = injectedApp
+
+	cmd := &cobra.Command{
+		Use:   "bin",
+		Short: "Install and manage avalanchego and subnet-evm versions",
+		Long: `Binaries are installed into a cache shared by all projects
+(--bin-cache-dir, default ` + binaries.DefaultDir() + `) as <name>/<version>/<exe>.
+
+Releases are downloaded from GitHub and verified against the release checksums.
+Releases without checksums (avalanchego has none) are trusted the first time a
+version is installed, and later installs of it must have the same sha256, unless
+installed with 'ggt bin install --no-verify' or their source sets "insecure":
+true in "bin-sources". To use a mirror (e.g. file:// on an offline machine)
+set "bin-sources" in ~/.config/ggt.json:
+
+  {"bin-sources": {"subnetevm": {
+    "url": "file:///mnt/mirror/subnet-evm_{v}_{os}_{arch}.tar.gz",
+    "checksums": "file:///mnt/mirror/subnet-evm_{v}_checksums.txt"}}}
+
+{version} is the version as given (v0.4.8), {v} is without the v, and {os} and
+{arch} are the Go OS and architecture. A source without a "url" keeps the
+default one, e.g. {"bin-sources": {"avalanchego": {"insecure": true}}}.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.PersistentFlags().String("bin-cache-dir", "", "Where binaries are installed (also BIN_CACHE_DIR)")
+	_ = viper.BindPFlag("bin-cache-dir", cmd.PersistentFlags().Lookup("bin-cache-dir"))
+
+	cmd.AddCommand(newInstallCmd())
+	cmd.AddCommand(newListCmd())
+	cmd.AddCommand(newRemoveCmd())
+	cmd.AddCommand(newUseCmd())
+	return cmd
+}
+
+// Cache is the binary cache from --bin-cache-dir, BIN_CACHE_DIR or the config file
+func Cache() *binaries.Cache {
+	return binaries.NewCache(viper.GetString("bin-cache-dir"))
+}
+
+// Sources are the default release sources with any "bin-sources" from the config file on top
+func Sources() (map[string]binaries.Source, error) {
+	sources := binaries.DefaultSources()
+	configured := map[string]binaries.Source{}
+	if err := viper.UnmarshalKey("bin-sources", &configured); err != nil {
+		return nil, fmt.Errorf("invalid bin-sources: %w", err)
+	}
+	for name, src := range configured {
+		if src.URL == "" {
+			src.URL, src.Checksums = sources[name].URL, sources[name].Checksums
+		}
+		sources[name] = src
+	}
+	return sources, nil
+}
+
+// Resolve returns the path of version of name in the cache, installing it if
+// needed. An empty version is the one set with 'ggt bin use'.
+func Resolve(name string, version string) (string, error) {
+	cache := Cache()
+	in, err := cache.Get(name, version)
+	if err == nil {
+		return in.Path, nil
+	}
+	if version == "" || !errors.Is(err, binaries.ErrNotInstalled) {
+		return "", err
+	}
+	sources, err := Sources()
+	if err != nil {
+		return "", err
+	}
+	app.Log.Infof("Installing %s %s into %s...", name, version, cache.Dir)
+	in, err = cache.Install(name, version, sources[name], false)
+	if err != nil {
+		return "", err
+	}
+	logInstalled(in)
+	return in.Path, nil
+}
+
+// ResolveLocal resolves a version from the cache and links it into dir as
+// <exe>-<version>, the names 'ggt init' has always used, unless that exists.
+func ResolveLocal(dir string, name string, version string) (string, error) {
+	path, err := Resolve(name, version)
+	if err != nil {
+		return "", err
+	}
+	fn := filepath.Join(dir, fmt.Sprintf("%s-%s", binaries.Binaries[name].Exe, version))
+	if _, err := os.Lstat(fn); err == nil {
+		app.Log.Infof("File exists, skipping %s", fn)
+		return fn, nil
+	}
+	app.Log.Infof("Linking %s to %s", path, fn)
+	return fn, utils.LinkFile(path, fn)
+}
+
+func logInstalled(in *binaries.Installed) {
+	switch in.Verified {
+	case binaries.VerifiedChecksums:
+		app.Log.Infof("Verified %s against %s", in.URL, in.Checksums)
+	case binaries.VerifiedRecorded:
+		app.Log.Infof("Verified %s against the sha256 %s recorded when %s %s was first installed", in.URL, in.SHA256, in.Name, in.Version)
+	case binaries.VerifiedFirstUse:
+		app.Log.Warnf("%s has no checksums file, %s was not verified, recorded its sha256 %s to verify later installs against", in.URL, in.Path, in.SHA256)
+	default:
+		app.Log.Warnf("%s was not verified (sha256 %s)", in.Path, in.SHA256)
+	}
+	app.Log.Infof("Installed %s %s to %s", in.Name, in.Version, in.Path)
+}
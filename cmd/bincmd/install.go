@@ -0,0 +1,55 @@
+package bincmd
+
+import (
+	"github.com/lasthyphen/ecctools/pkg/binaries"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newInstallCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "install name version",
+		Short: "Download a release of avalanchego or subnetevm into the cache",
+		Long: `Downloads and verifies a release, e.g.
+
+  ggt bin install avalanchego v1.9.7
+  ggt bin install subnetevm v0.4.8 --use
+
+--url and --checksums override the release source for this install, with the
+same placeholders as "bin-sources" (see 'ggt bin --help'). A release without
+checksums must match the sha256 recorded when the version was first installed,
+unless --no-verify is given.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			name, version := args[0], args[1]
+			sources, err := Sources()
+			if err != nil {
+				return err
+			}
+			src := sources[name]
+			if viper.GetString("url") != "" {
+				src = binaries.Source{URL: viper.GetString("url"), Checksums: viper.GetString("checksums")}
+			}
+			src.Insecure = src.Insecure || viper.GetBool("no-verify")
+
+			cache := Cache()
+			app.Log.Infof("Installing %s %s into %s...", name, version, cache.Dir)
+			in, err := cache.Install(name, version, src, viper.GetBool("force"))
+			if err != nil {
+				return err
+			}
+			logInstalled(in)
+			if viper.GetBool("use") {
+				return cache.Use(name, version)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().String("url", "", "Release archive URL template, instead of the configured source")
+	cmd.Flags().String("checksums", "", "Checksums file URL template to verify --url against")
+	cmd.Flags().Bool("no-verify", false, "Don't check a release without checksums against the sha256 recorded by its first install")
+	cmd.Flags().Bool("force", false, "Reinstall if the version is already installed")
+	cmd.Flags().Bool("use", false, "Also make this the current version, like 'ggt bin use'")
+	return cmd
+}
@@ -0,0 +1,42 @@
+package bincmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List installed versions, * is the current one",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			list, err := Cache().List()
+			if err != nil {
+				return err
+			}
+			if viper.GetBool("json") {
+				b, err := json.MarshalIndent(list, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(b))
+				return nil
+			}
+			for _, in := range list {
+				current := " "
+				if in.Current {
+					current = "*"
+				}
+				fmt.Printf("%s %-12s %-10s %s\n", current, in.Name, in.Version, in.Path)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Bool("json", false, "Output JSON, including where each version was downloaded from")
+	return cmd
+}
@@ -0,0 +1,23 @@
+package bincmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove name version",
+		Short: "Delete an installed version from the cache",
+		Long: `Nodes prepared with the version link to the cache, so they will not start
+until they are prepared again.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := Cache().Remove(args[0], args[1]); err != nil {
+				return err
+			}
+			app.Log.Infof("Removed %s %s", args[0], args[1])
+			return nil
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,23 @@
+package bincmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newUseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "use name version",
+		Short: "Make an installed version the default for 'ggt node prepare'",
+		Long: `'ggt node prepare' uses the current avalanchego when neither --ava-bin nor
+--ava-version is given.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := Cache().Use(args[0], args[1]); err != nil {
+				return err
+			}
+			app.Log.Infof("Using %s %s", args[0], args[1])
+			return nil
+		},
+	}
+	return cmd
+}
@@ -1,10 +1,10 @@
 package castcmd
 
 import (
+	"context"
 	"fmt"
-	"strings"
 
-	gocmd "github.com/go-cmd/cmd"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/lasthyphen/ecctools/pkg/utils"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -16,7 +16,7 @@ func newBalancesCmd() *cobra.Command {
 
 	cmd := &cobra.Command{
 		Use:   "balances",
-		Short: "",
+		Short: "Show the balance of every user in the accounts.json file",
 		Long:  ``,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var err error
@@ -24,16 +24,19 @@ func newBalancesCmd() *cobra.Command {
 			accounts, err := utils.LoadJSON(viper.GetString("accounts"))
 			cobra.CheckErr(err)
 
+			getBalance := balanceOf
+			if viper.GetBool("use-cast") {
+				getBalance = castBalanceOf
+			}
+
 			balances := "{}"
 
 			accounts.ForEach(func(key gjson.Result, value gjson.Result) bool {
-				envCmd := gocmd.NewCmd("cast", "balance", value.Get("addr").String())
-				status := <-envCmd.Start()
-				if len(status.Stderr) > 0 {
-					err = fmt.Errorf(strings.Join(status.Stderr, "\n"))
+				var result string
+				result, err = getBalance(value.Get("addr").String())
+				if err != nil {
 					return false
 				}
-				result := status.Stdout[0]
 				ether := utils.ToDecimal(result, 18)
 				balances, _ = sjson.Set(balances, key.String(), ether)
 				return true
@@ -49,3 +52,26 @@ func newBalancesCmd() *cobra.Command {
 
 	return cmd
 }
+
+// Returns the balance in wei as a string
+func balanceOf(addr string) (string, error) {
+	client, err := dialEth()
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+
+	bal, err := client.BalanceAt(context.Background(), common.HexToAddress(addr), nil)
+	if err != nil {
+		return "", err
+	}
+	return bal.String(), nil
+}
+
+func castBalanceOf(addr string) (string, error) {
+	stdout, err := runCast("balance", addr)
+	if err != nil {
+		return "", err
+	}
+	return stdout[0], nil
+}
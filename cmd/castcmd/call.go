@@ -1,11 +1,12 @@
 package castcmd
 
 import (
+	"context"
 	"fmt"
-	"os"
 	"strings"
 
-	gocmd "github.com/go-cmd/cmd"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/lasthyphen/ecctools/pkg/utils"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -16,8 +17,10 @@ func newCallCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "call from contract fnSig [args]",
 		Short: "Call a contract fnSig from a user in the accounts.json file",
-		Long:  `Use --verbose flag to see the full 'cast' command that gets run`,
-		Args:  cobra.MinimumNArgs(3),
+		Long: `Return values are printed as raw hex, unless fnSig declares them, e.g. "balanceOf(address)(uint256)"
+
+Use --verbose flag to see the full 'cast' command that gets run with --use-cast`,
+		Args: cobra.MinimumNArgs(3),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var err error
 
@@ -33,21 +36,22 @@ func newCallCmd() *cobra.Command {
 
 			// If any of the args have a user name, resolve to an addr
 			args = utils.ResolveAccountAddrs(accounts, args)
+			args = utils.ResolveContractAddrs(contracts, args)
+			args = utils.ResolveAmounts(args)
 
-			allArgs := []string{"call", "--from", fromAddr, contractAddr, fnSig}
-			allArgs = append(allArgs, args[3:]...)
-			envCmd := gocmd.NewCmd("cast", allArgs...)
-
-			if viper.GetBool("verbose") {
-				fmt.Fprintf(os.Stderr, "%s %s\n\n", envCmd.Name, strings.Join(envCmd.Args, " "))
+			var out []string
+			if viper.GetBool("use-cast") {
+				allArgs := []string{"call", "--from", fromAddr, contractAddr, fnSig}
+				allArgs = append(allArgs, args[3:]...)
+				out, err = runCast(allArgs...)
+			} else {
+				out, err = call(fromAddr, contractAddr, fnSig, args[3:])
 			}
-
-			status := <-envCmd.Start()
-			if len(status.Stderr) > 0 {
-				return fmt.Errorf(strings.Join(status.Stderr, "\n"))
+			if err != nil {
+				return err
 			}
 
-			fmt.Println(strings.Join(status.Stdout, "\n"))
+			fmt.Println(strings.Join(out, "\n"))
 
 			return nil
 		},
@@ -55,3 +59,32 @@ func newCallCmd() *cobra.Command {
 
 	return cmd
 }
+
+func call(fromAddr string, contractAddr string, sig string, args []string) ([]string, error) {
+	fn, err := parseFnSig(sig)
+	if err != nil {
+		return nil, err
+	}
+	data, err := fn.encodeCall(args)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := dialEth()
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	to := common.HexToAddress(contractAddr)
+	msg := ethereum.CallMsg{
+		From: common.HexToAddress(fromAddr),
+		To:   &to,
+		Data: data,
+	}
+	result, err := client.CallContract(context.Background(), msg, nil)
+	if err != nil {
+		return nil, err
+	}
+	return fn.decodeResult(result)
+}
@@ -1,9 +1,15 @@
 package castcmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"strings"
 
+	gocmd "github.com/go-cmd/cmd"
 	"github.com/lasthyphen/ecctools/pkg/application"
+	"github.com/lasthyphen/ecctools/pkg/nodeclient"
+	"github.com/lasthyphen/ecctools/pkg/utils"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -15,11 +21,16 @@ func NewCmd(injectedApp *application.GoGoTools) *cobra.Command {
 
 	cmd := &cobra.Command{
 		Use:   "cast",
-		Short: "Convenience wrapper around the `cast` command (https://book.getfoundry.sh)",
-		Long: `Convenience wrapper around the 'cast' command (https://book.getfoundry.sh)
+		Short: "Ergonomic EVM commands modeled on `cast` (https://book.getfoundry.sh)",
+		Long: `Ergonomic EVM commands modeled on 'cast' (https://book.getfoundry.sh)
 
 This command uses the default or supplied accounts.json and contracts.json files
-to make it a bit more ergonomic to issue cast commands to your node.
+to make it a bit more ergonomic to issue txs and calls to your node.
+
+The commands talk to the node directly, so Foundry does not need to be installed.
+The EVM RPC URL is taken from --eth-rpc-url (or ETH_RPC_URL), or can be looked
+up by chain name with '--node MyNodeV1 --chain MyChain'.
+Use --use-cast to shell out to the 'cast' binary instead.
 		`,
 		Run: func(cmd *cobra.Command, args []string) {
 			err := cmd.Help()
@@ -35,9 +46,69 @@ to make it a bit more ergonomic to issue cast commands to your node.
 	cmd.PersistentFlags().String("contracts", "contracts.json", "JSON of contract addresses")
 	_ = viper.BindPFlag("contracts", cmd.PersistentFlags().Lookup("contracts"))
 
+	cmd.PersistentFlags().String("eth-rpc-url", "", "EVM RPC URL (also ETH_RPC_URL)")
+	_ = viper.BindPFlag("eth-rpc-url", cmd.PersistentFlags().Lookup("eth-rpc-url"))
+
+	cmd.PersistentFlags().String("node", "", "Node work-dir to send requests to (instead of --eth-rpc-url)")
+	_ = viper.BindPFlag("node", cmd.PersistentFlags().Lookup("node"))
+
+	cmd.PersistentFlags().String("chain", "C", "Name of the blockchain on --node to use")
+	_ = viper.BindPFlag("chain", cmd.PersistentFlags().Lookup("chain"))
+
+	cmd.PersistentFlags().Bool("use-cast", false, "Shell out to the Foundry 'cast' binary instead")
+	_ = viper.BindPFlag("use-cast", cmd.PersistentFlags().Lookup("use-cast"))
+
 	cmd.AddCommand(newBalancesCmd())
 	cmd.AddCommand(newCallCmd())
 	cmd.AddCommand(newSendCmd())
 	cmd.AddCommand(newSendEthCmd())
 	return cmd
 }
+
+// runCast shells out to the Foundry 'cast' binary and returns its stdout
+func runCast(args ...string) ([]string, error) {
+	url, err := rpcURL()
+	if err == nil {
+		args = append(args, "--rpc-url", url)
+	}
+	envCmd := gocmd.NewCmd("cast", args...)
+
+	if viper.GetBool("verbose") {
+		fmt.Fprintf(os.Stderr, "%s %s\n\n", envCmd.Name, strings.Join(envCmd.Args, " "))
+	}
+
+	status := <-envCmd.Start()
+	if status.Error != nil {
+		return nil, status.Error
+	}
+	if len(status.Stderr) > 0 {
+		return nil, fmt.Errorf(strings.Join(status.Stderr, "\n"))
+	}
+	return status.Stdout, nil
+}
+
+// rpcURL is either the explicit --eth-rpc-url, or the RPC of --chain on the --node work-dir
+func rpcURL() (string, error) {
+	if url := viper.GetString("eth-rpc-url"); url != "" {
+		return url, nil
+	}
+	workDir := viper.GetString("node")
+	if workDir == "" {
+		return "", fmt.Errorf("must supply --eth-rpc-url flag, ETH_RPC_URL env or --node")
+	}
+	uri := utils.ResolveNodeURL(workDir)
+	chain := viper.GetString("chain")
+	if chain == "" || chain == "C" {
+		return fmt.Sprintf("%s/ext/bc/C/rpc", uri), nil
+	}
+
+	client := nodeclient.New(uri)
+	blockchain, err := client.FindBlockchain(context.Background(), chain)
+	if err != nil {
+		return "", err
+	}
+	if blockchain == nil {
+		return "", fmt.Errorf("blockchain %s not found on node %s", chain, workDir)
+	}
+	return client.RPC(blockchain.ID.String()), nil
+}
@@ -0,0 +1,338 @@
+package castcmd
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+)
+
+// Native replacements for the handful of `cast` subcommands we used to shell out to.
+
+func dialEth() (*ethclient.Client, error) {
+	url, err := rpcURL()
+	if err != nil {
+		return nil, err
+	}
+	client, err := ethclient.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to %s: %w", url, err)
+	}
+	return client, nil
+}
+
+// fnSig is a parsed function signature like "transfer(address,uint256)(bool)"
+type fnSig struct {
+	method  abi.Method
+	inputs  abi.Arguments
+	outputs abi.Arguments
+}
+
+func parseFnSig(sig string) (*fnSig, error) {
+	open := strings.Index(sig, "(")
+	if open < 1 {
+		return nil, fmt.Errorf("invalid function signature %q", sig)
+	}
+	name := sig[:open]
+
+	inTypes, rest, err := splitTypeList(sig[open:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid function signature %q: %w", sig, err)
+	}
+	var outTypes []string
+	if rest != "" {
+		outTypes, rest, err = splitTypeList(rest)
+		if err != nil || rest != "" {
+			return nil, fmt.Errorf("invalid function signature %q", sig)
+		}
+	}
+
+	inputs, err := newArguments(inTypes)
+	if err != nil {
+		return nil, err
+	}
+	outputs, err := newArguments(outTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	method := abi.NewMethod(name, name, abi.Function, "", false, false, inputs, outputs)
+	return &fnSig{method: method, inputs: inputs, outputs: outputs}, nil
+}
+
+// splitTypeList takes "(a,b)rest" and returns [a b] and rest
+func splitTypeList(s string) ([]string, string, error) {
+	if !strings.HasPrefix(s, "(") {
+		return nil, "", fmt.Errorf("expected '('")
+	}
+	end := strings.Index(s, ")")
+	if end < 0 {
+		return nil, "", fmt.Errorf("expected ')'")
+	}
+	inner := strings.TrimSpace(s[1:end])
+	if strings.Contains(inner, "(") {
+		return nil, "", fmt.Errorf("tuple types are not supported")
+	}
+	out := []string{}
+	if inner != "" {
+		for _, t := range strings.Split(inner, ",") {
+			out = append(out, strings.TrimSpace(t))
+		}
+	}
+	return out, s[end+1:], nil
+}
+
+func newArguments(typeNames []string) (abi.Arguments, error) {
+	args := abi.Arguments{}
+	for _, name := range typeNames {
+		typ, err := abi.NewType(name, "", nil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid type %q: %w", name, err)
+		}
+		args = append(args, abi.Argument{Type: typ})
+	}
+	return args, nil
+}
+
+// encodeCall packs the string args according to the signature and returns the calldata
+func (f *fnSig) encodeCall(args []string) ([]byte, error) {
+	if len(args) != len(f.inputs) {
+		return nil, fmt.Errorf("%s expects %d args, got %d", f.method.Sig, len(f.inputs), len(args))
+	}
+	values := []interface{}{}
+	for i, arg := range args {
+		v, err := parseArg(f.inputs[i].Type, arg)
+		if err != nil {
+			return nil, fmt.Errorf("arg %d (%s): %w", i, f.inputs[i].Type.String(), err)
+		}
+		values = append(values, v)
+	}
+	packed, err := f.inputs.Pack(values...)
+	if err != nil {
+		return nil, err
+	}
+	return append(f.method.ID, packed...), nil
+}
+
+// decodeResult returns one string per output value, or the raw hex if the
+// signature did not declare any return types (same as `cast call`)
+func (f *fnSig) decodeResult(data []byte) ([]string, error) {
+	if len(f.outputs) == 0 {
+		return []string{hexutil.Encode(data)}, nil
+	}
+	values, err := f.outputs.Unpack(data)
+	if err != nil {
+		return nil, err
+	}
+	out := []string{}
+	for _, v := range values {
+		out = append(out, formatValue(v))
+	}
+	return out, nil
+}
+
+func parseArg(typ abi.Type, arg string) (interface{}, error) {
+	goType := typ.GetType()
+
+	switch typ.T {
+	case abi.AddressTy:
+		if !common.IsHexAddress(arg) {
+			return nil, fmt.Errorf("invalid address %q", arg)
+		}
+		return common.HexToAddress(arg), nil
+	case abi.BoolTy:
+		return strconv.ParseBool(arg)
+	case abi.StringTy:
+		return arg, nil
+	case abi.IntTy, abi.UintTy:
+		n, ok := new(big.Int).SetString(arg, 0)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer %q", arg)
+		}
+		// abi.Pack rejects out of range big.Ints, and Convert would silently truncate the rest
+		if typ.T == abi.UintTy && (n.Sign() < 0 || n.BitLen() > typ.Size) {
+			return nil, fmt.Errorf("%s is out of range for uint%d", arg, typ.Size)
+		}
+		if typ.T == abi.IntTy {
+			limit := new(big.Int).Lsh(big.NewInt(1), uint(typ.Size-1))
+			if n.Cmp(new(big.Int).Neg(limit)) < 0 || n.Cmp(limit) >= 0 {
+				return nil, fmt.Errorf("%s is out of range for int%d", arg, typ.Size)
+			}
+		}
+		if goType == reflect.TypeOf(n) {
+			return n, nil
+		}
+		if typ.T == abi.UintTy {
+			return reflect.ValueOf(n.Uint64()).Convert(goType).Interface(), nil
+		}
+		return reflect.ValueOf(n.Int64()).Convert(goType).Interface(), nil
+	case abi.BytesTy:
+		return hexutil.Decode(arg)
+	case abi.FixedBytesTy:
+		b, err := hexutil.Decode(arg)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) > typ.Size {
+			return nil, fmt.Errorf("%q is longer than %d bytes", arg, typ.Size)
+		}
+		v := reflect.New(goType).Elem()
+		reflect.Copy(v, reflect.ValueOf(b))
+		return v.Interface(), nil
+	case abi.SliceTy, abi.ArrayTy:
+		elems := splitListArg(arg)
+		if typ.T == abi.ArrayTy && len(elems) != typ.Size {
+			return nil, fmt.Errorf("expected %d elements, got %d", typ.Size, len(elems))
+		}
+		var v reflect.Value
+		if typ.T == abi.ArrayTy {
+			v = reflect.New(goType).Elem()
+		} else {
+			v = reflect.MakeSlice(goType, len(elems), len(elems))
+		}
+		for i, e := range elems {
+			ev, err := parseArg(*typ.Elem, e)
+			if err != nil {
+				return nil, err
+			}
+			v.Index(i).Set(reflect.ValueOf(ev))
+		}
+		return v.Interface(), nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", typ.String())
+	}
+}
+
+// splitListArg turns "[a,b,c]" into [a b c]
+func splitListArg(arg string) []string {
+	inner := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(arg), "["), "]"))
+	if inner == "" {
+		return []string{}
+	}
+	out := []string{}
+	for _, e := range strings.Split(inner, ",") {
+		out = append(out, strings.TrimSpace(e))
+	}
+	return out
+}
+
+func formatValue(v interface{}) string {
+	switch t := v.(type) {
+	case common.Address:
+		return t.Hex()
+	case []byte:
+		return hexutil.Encode(t)
+	case *big.Int:
+		return t.String()
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Array && rv.Type().Elem().Kind() == reflect.Uint8 {
+		b := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(b), rv)
+		return hexutil.Encode(b)
+	}
+	return fmt.Sprint(v)
+}
+
+func decodeEthPrivateKey(pk string) (*ecdsa.PrivateKey, error) {
+	key, err := ethcrypto.HexToECDSA(strings.TrimPrefix(pk, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode private key: %w", err)
+	}
+	return key, nil
+}
+
+// sendTx signs a dynamic fee tx with key, sends it and waits for the receipt
+func sendTx(ctx context.Context, client *ethclient.Client, key *ecdsa.PrivateKey, to common.Address, value *big.Int, data []byte) (*types.Receipt, error) {
+	from := ethcrypto.PubkeyToAddress(key.PublicKey)
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get chainID: %w", err)
+	}
+	nonce, err := client.PendingNonceAt(ctx, from)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get nonce for %s: %w", from, err)
+	}
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get latest header: %w", err)
+	}
+
+	msg := ethereum.CallMsg{From: from, To: &to, Value: value, Data: data}
+	if head.BaseFee == nil {
+		// Chains without EIP-1559 only take legacy txs
+		if msg.GasPrice, err = client.SuggestGasPrice(ctx); err != nil {
+			return nil, fmt.Errorf("unable to get gas price: %w", err)
+		}
+	} else {
+		if msg.GasTipCap, err = client.SuggestGasTipCap(ctx); err != nil {
+			return nil, fmt.Errorf("unable to get gas tip: %w", err)
+		}
+		// Same headroom that bind.TransactOpts uses
+		msg.GasFeeCap = new(big.Int).Add(msg.GasTipCap, new(big.Int).Mul(head.BaseFee, big.NewInt(2)))
+	}
+	gas, err := client.EstimateGas(ctx, msg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to estimate gas: %w", err)
+	}
+
+	var tx *types.Transaction
+	if head.BaseFee == nil {
+		tx = types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: msg.GasPrice,
+			Gas:      gas,
+			To:       &to,
+			Value:    value,
+			Data:     data,
+		})
+	} else {
+		tx = types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     nonce,
+			GasTipCap: msg.GasTipCap,
+			GasFeeCap: msg.GasFeeCap,
+			Gas:       gas,
+			To:        &to,
+			Value:     value,
+			Data:      data,
+		})
+	}
+	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(chainID), key)
+	if err != nil {
+		return nil, fmt.Errorf("unable to sign tx: %w", err)
+	}
+	if err := client.SendTransaction(ctx, signedTx); err != nil {
+		return nil, fmt.Errorf("unable to send tx: %w", err)
+	}
+	return bind.WaitMined(ctx, client, signedTx)
+}
+
+// printReceipt prints the receipt as the node returns it, which is what
+// cast send --json prints, rather than geth's marshalling of it
+func printReceipt(receipt *types.Receipt) error {
+	url, err := rpcURL()
+	if err != nil {
+		return err
+	}
+	raw, err := utils.DefaultRPCClient().Call(url, "eth_getTransactionReceipt", []string{receipt.TxHash.Hex()})
+	if err != nil {
+		return fmt.Errorf("unable to get receipt for %s: %w", receipt.TxHash, err)
+	}
+	fmt.Println(string(raw))
+	return nil
+}
@@ -0,0 +1,57 @@
+package castcmd
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_EncodeCall(t *testing.T) {
+	fn, err := parseFnSig("mintNativeCoin(address,uint256)")
+	require.NoError(t, err)
+	data, err := fn.encodeCall([]string{"0x70997970C51812dc3A010C7d01b50e0d17dc79C8", "1000000000000000000"})
+	require.NoError(t, err)
+	require.Equal(t, "0x4f5aaaba"+
+		"00000000000000000000000070997970c51812dc3a010c7d01b50e0d17dc79c8"+
+		"0000000000000000000000000000000000000000000000000de0b6b3a7640000", hexutil.Encode(data))
+}
+
+func Test_DecodeResult(t *testing.T) {
+	fn, err := parseFnSig("readAllowList(address)(uint256)")
+	require.NoError(t, err)
+	out, err := fn.decodeResult(hexutil.MustDecode("0x0000000000000000000000000000000000000000000000000000000000000002"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"2"}, out)
+
+	fn, err = parseFnSig("getFeeConfigLastChangedAt()")
+	require.NoError(t, err)
+	out, err = fn.decodeResult([]byte{1})
+	require.NoError(t, err)
+	require.Equal(t, []string{"0x01"}, out)
+}
+
+func Test_ParseArgRange(t *testing.T) {
+	for _, tc := range []struct {
+		typ string
+		arg string
+		ok  bool
+	}{
+		{"uint8", "255", true},
+		{"uint8", "256", false},
+		{"uint256", "-1", false},
+		{"int16", "-32768", true},
+		{"int16", "32768", false},
+		{"int128", "0x80000000000000000000000000000000", false},
+	} {
+		typ, err := abi.NewType(tc.typ, "", nil)
+		require.NoError(t, err)
+		_, err = parseArg(typ, tc.arg)
+		if tc.ok {
+			require.NoError(t, err, "%s %s", tc.typ, tc.arg)
+		} else {
+			require.ErrorContains(t, err, "out of range", "%s %s", tc.typ, tc.arg)
+		}
+	}
+}
@@ -1,11 +1,11 @@
 package castcmd
 
 import (
+	"context"
 	"fmt"
-	"os"
 	"strings"
 
-	gocmd "github.com/go-cmd/cmd"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/lasthyphen/ecctools/pkg/utils"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -16,7 +16,7 @@ func newSendCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "send from contract fnSig [args]",
 		Short: "Sign and pub a tx from a user in the accounts.json file to contract",
-		Long:  `Use --verbose flag to see the full 'cast' command that gets run`,
+		Long:  `Use --verbose flag to see the full 'cast' command that gets run with --use-cast`,
 		Args:  cobra.MinimumNArgs(3),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var err error
@@ -34,25 +34,50 @@ func newSendCmd() *cobra.Command {
 
 			// If any of the args have a user name, resolve to an addr
 			args = utils.ResolveAccountAddrs(accounts, args)
+			args = utils.ResolveContractAddrs(contracts, args)
+			args = utils.ResolveAmounts(args)
 
-			allArgs := []string{"send", "--json", "--from", fromAddr, "--private-key", fromPk, contractAddr, fnSig}
-			allArgs = append(allArgs, args[3:]...)
-			envCmd := gocmd.NewCmd("cast", allArgs...)
-
-			if viper.GetBool("verbose") {
-				fmt.Fprintf(os.Stderr, "%s %s\n\n", envCmd.Name, strings.Join(envCmd.Args, " "))
-			}
-
-			status := <-envCmd.Start()
-			if len(status.Stderr) > 0 {
-				return fmt.Errorf(strings.Join(status.Stderr, "\n"))
+			if viper.GetBool("use-cast") {
+				allArgs := []string{"send", "--json", "--from", fromAddr, "--private-key", fromPk, contractAddr, fnSig}
+				allArgs = append(allArgs, args[3:]...)
+				out, err := runCast(allArgs...)
+				if err != nil {
+					return err
+				}
+				fmt.Println(strings.Join(out, "\n"))
+				return nil
 			}
 
-			fmt.Println(strings.Join(status.Stdout, "\n"))
-
-			return nil
+			return send(fromPk, contractAddr, fnSig, args[3:])
 		},
 	}
 
 	return cmd
 }
+
+func send(fromPk string, contractAddr string, sig string, args []string) error {
+	key, err := decodeEthPrivateKey(fromPk)
+	if err != nil {
+		return err
+	}
+	fn, err := parseFnSig(sig)
+	if err != nil {
+		return err
+	}
+	data, err := fn.encodeCall(args)
+	if err != nil {
+		return err
+	}
+
+	client, err := dialEth()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	receipt, err := sendTx(context.Background(), client, key, common.HexToAddress(contractAddr), nil, data)
+	if err != nil {
+		return err
+	}
+	return printReceipt(receipt)
+}
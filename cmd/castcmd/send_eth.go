@@ -1,10 +1,12 @@
 package castcmd
 
 import (
+	"context"
 	"fmt"
+	"math/big"
 	"strings"
 
-	gocmd "github.com/go-cmd/cmd"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/lasthyphen/ecctools/pkg/utils"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -14,7 +16,7 @@ func newSendEthCmd() *cobra.Command {
 
 	cmd := &cobra.Command{
 		Use:   "send-eth from to amount",
-		Short: "",
+		Short: "Send native coin from a user in the accounts.json file (amount in wei or e.g. 0.1ether)",
 		Long:  ``,
 		Args:  cobra.ExactArgs(3),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -31,17 +33,45 @@ func newSendEthCmd() *cobra.Command {
 				toAddr = args[1]
 			}
 
-			envCmd := gocmd.NewCmd("cast", "send", "--json", "--from", fromAddr, "--private-key", fromPk, "--value", args[2], toAddr)
-			status := <-envCmd.Start()
-			if len(status.Stderr) > 0 {
-				return fmt.Errorf(strings.Join(status.Stderr, "\n"))
+			if viper.GetBool("use-cast") {
+				out, err := runCast("send", "--json", "--from", fromAddr, "--private-key", fromPk, "--value", args[2], toAddr)
+				if err != nil {
+					return err
+				}
+				fmt.Println(strings.Join(out, "\n"))
+				return nil
 			}
 
-			fmt.Println(strings.Join(status.Stdout, "\n"))
-
-			return nil
+			amount := utils.ResolveAmounts([]string{args[2]})[0]
+			return sendEth(fromPk, toAddr, amount)
 		},
 	}
 
 	return cmd
 }
+
+func sendEth(fromPk string, toAddr string, amount string) error {
+	key, err := decodeEthPrivateKey(fromPk)
+	if err != nil {
+		return err
+	}
+	if !common.IsHexAddress(toAddr) {
+		return fmt.Errorf("invalid address %q", toAddr)
+	}
+	value, ok := new(big.Int).SetString(amount, 0)
+	if !ok {
+		return fmt.Errorf("invalid amount %q", amount)
+	}
+
+	client, err := dialEth()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	receipt, err := sendTx(context.Background(), client, key, common.HexToAddress(toAddr), value, nil)
+	if err != nil {
+		return err
+	}
+	return printReceipt(receipt)
+}
@@ -0,0 +1,32 @@
+package genesiscmd
+
+import (
+	"fmt"
+
+	"github.com/lasthyphen/ecctools/pkg/application"
+	"github.com/spf13/cobra"
+)
+
+var app *application.GoGoTools
+
+func NewCmd(injectedApp *application.GoGoTools) *cobra.Command {
+	app = injectedApp
+
+	cmd := &cobra.Command{
+		Use:          "genesis",
+		Short:        "Generate genesis files",
+		Long:         ``,
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := cmd.Help()
+			if err != nil {
+				fmt.Println(err)
+			}
+		},
+	}
+
+	cmd.AddCommand(newPrimaryCmd())
+	cmd.AddCommand(newSubnetEVMCmd())
+
+	return cmd
+}
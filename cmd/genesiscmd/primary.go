@@ -0,0 +1,227 @@
+package genesiscmd
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lasthyphen/dijetsnode/ids"
+	"github.com/lasthyphen/ecctools/cmd/walletcmd"
+	"github.com/lasthyphen/ecctools/pkg/configs"
+	"github.com/lasthyphen/ecctools/pkg/genesis"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/lasthyphen/utilitychain/plugin/evm"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tidwall/gjson"
+)
+
+func newPrimaryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "primary",
+		Short: "Generate a primary network genesis (ava-genesis.json)",
+		Long: `Generates the genesis of a custom primary network. Funds go to accounts.json
+names, as name=amount in AVAX. P-chain funds and --staked-funds can be locked
+for a while after the start time with name=amount@duration.
+
+--staked-funds are staked by --stakers (NodeIDs or node work dirs) for
+--stake-duration, and returned to the account after that.
+
+The output only depends on the flags and accounts.json, so it is the same every
+time for the same inputs. --start-time defaults to the start of the current UTC
+day and has to be in the past.
+
+  ggt genesis primary --network-id 4242 --start-time 2023-06-01T00:00:00Z \
+    --x-alloc owner=1000 --c-alloc owner=1000,alice=10 --stakers Node1,Node2`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			accounts, err := loadAccounts(viper.GetString("accounts"))
+			if err != nil {
+				return err
+			}
+
+			opts := genesis.PrimaryOptions{
+				NetworkID:     viper.GetUint32("network-id"),
+				StakeDuration: viper.GetDuration("stake-duration"),
+				StakeOffset:   viper.GetDuration("stake-offset"),
+				CChainID:      viper.GetUint64("c-chain-id"),
+				DelegationFee: viper.GetUint32("delegation-fee"),
+				Message:       viper.GetString("message"),
+			}
+			if opts.StartTime, err = parseStartTime(viper.GetString("start-time")); err != nil {
+				return err
+			}
+			if opts.XAlloc, err = resolveAmounts(accounts, viper.GetStringSlice("x-alloc")); err != nil {
+				return fmt.Errorf("--x-alloc: %w", err)
+			}
+			if opts.PAlloc, err = resolveAmounts(accounts, viper.GetStringSlice("p-alloc")); err != nil {
+				return fmt.Errorf("--p-alloc: %w", err)
+			}
+			if opts.StakedFunds, err = resolveAmounts(accounts, viper.GetStringSlice("staked-funds")); err != nil {
+				return fmt.Errorf("--staked-funds: %w", err)
+			}
+			if opts.CAlloc, err = resolveCAlloc(accounts, viper.GetStringSlice("c-alloc")); err != nil {
+				return fmt.Errorf("--c-alloc: %w", err)
+			}
+			reward, err := resolveAccount(accounts, viper.GetString("reward-addr"))
+			if err != nil {
+				return fmt.Errorf("--reward-addr: %w", err)
+			}
+			opts.RewardAddr = reward.Addr
+			if opts.Stakers, err = resolveStakers(viper.GetStringSlice("stakers")); err != nil {
+				return err
+			}
+
+			b, err := genesis.Primary(opts)
+			if err != nil {
+				return err
+			}
+			fn := viper.GetString("out")
+			if fn == "-" {
+				fmt.Println(string(b))
+				return nil
+			}
+			if err := utils.WriteFileBytes(fn, b); err != nil {
+				return err
+			}
+			app.Log.Infof("Wrote %s", fn)
+			return nil
+		},
+	}
+	cmd.Flags().String("out", configs.AvaGenesisFilename, "File to write the genesis to, or - for stdout")
+	cmd.Flags().String("accounts", configs.AccountsFilename, "JSON file with accounts (default is the built-in accounts)")
+	cmd.Flags().Uint32("network-id", 1337, "Network ID")
+	cmd.Flags().String("start-time", "", "Genesis start time, RFC3339 or unix seconds (default the start of today, UTC)")
+	cmd.Flags().Duration("stake-duration", 365*24*time.Hour, "How long the initial stakers validate for")
+	cmd.Flags().Duration("stake-offset", 90*time.Minute, "Offset between the end times of consecutive initial stakers")
+	cmd.Flags().StringSlice("x-alloc", []string{"owner=300000000"}, "X-chain funds, as name=amount")
+	cmd.Flags().StringSlice("p-alloc", []string{"owner=20000000", "owner=10000000@788h"}, "P-chain funds, as name=amount[@locked-for]")
+	cmd.Flags().StringSlice("staked-funds", []string{"admin=10000000@788h"}, "P-chain funds staked by --stakers, as name=amount[@locked-for]")
+	cmd.Flags().StringSlice("c-alloc", []string{"owner=50000000", "admin=50000000"}, "C-chain funds, as name=amount")
+	cmd.Flags().Uint64("c-chain-id", 43112, "C-chain EVM chain ID")
+	cmd.Flags().StringSlice("stakers", []string{}, "NodeIDs or node work dirs of the initial stakers (default the stakers in the built-in genesis)")
+	cmd.Flags().String("reward-addr", "owner", "Account that gets the initial stakers' rewards")
+	cmd.Flags().Uint32("delegation-fee", 1000000, "Delegation fee of the initial stakers, out of 1000000")
+	cmd.Flags().String("message", gjson.GetBytes(configs.AvaGenesis, "message").String(), "Genesis message")
+	return cmd
+}
+
+func parseStartTime(s string) (time.Time, error) {
+	if s == "" {
+		return genesis.DefaultStartTime(), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	secs, err := decimal.NewFromString(s)
+	if err != nil || !secs.IsInteger() {
+		return time.Time{}, fmt.Errorf("invalid --start-time %q, expected %s or unix seconds", s, time.RFC3339)
+	}
+	return time.Unix(secs.IntPart(), 0), nil
+}
+
+func resolveAccount(accounts *gjson.Result, name string) (genesis.Account, error) {
+	pk := accounts.Get(name).Get("pk").String()
+	if pk == "" {
+		return genesis.Account{}, fmt.Errorf("%s is not an account in accounts.json", name)
+	}
+	key, err := walletcmd.DecodePrivateKey(pk)
+	if err != nil {
+		return genesis.Account{}, fmt.Errorf("account %s: %w", name, err)
+	}
+	return genesis.Account{Name: name, Addr: key.Address(), EthAddr: evm.GetEthAddress(key)}, nil
+}
+
+// resolveAmounts parses name=amount[@locked-for] entries, amounts in AVAX
+func resolveAmounts(accounts *gjson.Result, entries []string) ([]genesis.Amount, error) {
+	out := []genesis.Amount{}
+	for _, entry := range entries {
+		name, amount, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid entry %q, expected name=amount", entry)
+		}
+		amount, lockedFor, locked := strings.Cut(amount, "@")
+		account, err := resolveAccount(accounts, name)
+		if err != nil {
+			return nil, err
+		}
+		nAVAX, err := parseAmount(amount, 9)
+		if err != nil || !nAVAX.IsUint64() {
+			return nil, fmt.Errorf("invalid amount %q for %s", amount, name)
+		}
+		a := genesis.Amount{Account: account, Amount: nAVAX.Uint64()}
+		if locked {
+			if a.Locktime, err = time.ParseDuration(lockedFor); err != nil {
+				return nil, fmt.Errorf("invalid lock duration %q for %s", lockedFor, name)
+			}
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+// resolveCAlloc parses name=amount entries, amounts in AVAX (18 decimals on the C-chain)
+func resolveCAlloc(accounts *gjson.Result, entries []string) (map[common.Address]*big.Int, error) {
+	alloc := map[common.Address]*big.Int{}
+	for _, entry := range entries {
+		name, amount, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid entry %q, expected name=amount", entry)
+		}
+		account, err := resolveAccount(accounts, name)
+		if err != nil {
+			return nil, err
+		}
+		wei, err := parseAmount(amount, 18)
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount %q for %s", amount, name)
+		}
+		if prev, ok := alloc[account.EthAddr]; ok {
+			wei.Add(wei, prev)
+		}
+		alloc[account.EthAddr] = wei
+	}
+	return alloc, nil
+}
+
+func parseAmount(s string, decimals int32) (*big.Int, error) {
+	d, err := decimal.NewFromString(s)
+	if err != nil || d.IsNegative() {
+		return nil, fmt.Errorf("invalid amount %q", s)
+	}
+	d = d.Shift(decimals)
+	if !d.IsInteger() {
+		return nil, fmt.Errorf("invalid amount %q", s)
+	}
+	return d.BigInt(), nil
+}
+
+// resolveStakers takes NodeIDs or node work dirs, defaulting to the built-in genesis stakers
+func resolveStakers(entries []string) ([]ids.NodeID, error) {
+	if len(entries) == 0 {
+		for _, nodeID := range gjson.GetBytes(configs.AvaGenesis, "initialStakers.#.nodeID").Array() {
+			entries = append(entries, nodeID.String())
+		}
+	}
+	out := []ids.NodeID{}
+	for _, entry := range entries {
+		if utils.DirExists(entry) {
+			nodeID, err := utils.NodeID(entry)
+			if err != nil {
+				return nil, fmt.Errorf("--stakers: %w", err)
+			}
+			out = append(out, nodeID)
+			continue
+		}
+		nodeID, err := ids.NodeIDFromString(entry)
+		if err != nil {
+			return nil, fmt.Errorf("--stakers: %s is not a NodeID or node work dir", entry)
+		}
+		out = append(out, nodeID)
+	}
+	return out, nil
+}
@@ -0,0 +1,210 @@
+package genesiscmd
+
+import (
+	"bufio"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lasthyphen/ecctools/pkg/configs"
+	"github.com/lasthyphen/ecctools/pkg/genesis"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tidwall/gjson"
+)
+
+// flag => precompile config key
+var precompileFlags = []struct{ flag, key string }{
+	{"deployer-allow-list", genesis.DeployerAllowList},
+	{"native-minter", genesis.NativeMinter},
+	{"tx-allow-list", genesis.TxAllowList},
+	{"fee-manager", genesis.FeeManager},
+	{"reward-manager", genesis.RewardManager},
+}
+
+func newSubnetEVMCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "subnetevm",
+		Short: "Generate a subnet-evm genesis file",
+		Long: `Generates a subnet-evm genesis from flags (or prompts for each of them with
+--interactive) and validates it before writing it to --out.
+
+Precompiles are enabled by giving them admins, as accounts.json names or 0x
+addresses. Use 'none' to disable one. --alloc funds accounts.json names (or
+addresses) with --balance each, or name=amount for a different amount, and
+defaults to every account in accounts.json.
+
+  ggt genesis subnetevm --chain-id 4321 --tx-allow-list none --alloc owner,alice=100ether`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			if viper.GetBool("interactive") {
+				if err := promptFlags(cmd); err != nil {
+					return err
+				}
+			}
+
+			accounts, err := loadAccounts(viper.GetString("accounts"))
+			if err != nil {
+				return err
+			}
+			opts := genesis.SubnetEVMOptions{
+				ChainID: viper.GetUint64("chain-id"),
+				FeeConfig: genesis.FeeConfig{
+					GasLimit:                 viper.GetUint64("gas-limit"),
+					TargetBlockRate:          viper.GetUint64("target-block-rate"),
+					MinBaseFee:               viper.GetUint64("min-base-fee"),
+					TargetGas:                viper.GetUint64("target-gas"),
+					BaseFeeChangeDenominator: viper.GetUint64("base-fee-change-denominator"),
+					MinBlockGasCost:          viper.GetUint64("min-block-gas-cost"),
+					MaxBlockGasCost:          viper.GetUint64("max-block-gas-cost"),
+					BlockGasCostStep:         viper.GetUint64("block-gas-cost-step"),
+				},
+				AllowFeeRecipients: viper.GetBool("allow-fee-recipients"),
+				Precompiles:        map[string][]common.Address{},
+			}
+			for _, p := range precompileFlags {
+				admins, err := resolveAddrs(accounts, viper.GetStringSlice(p.flag))
+				if err != nil {
+					return fmt.Errorf("--%s: %w", p.flag, err)
+				}
+				if len(admins) > 0 {
+					opts.Precompiles[p.key] = admins
+				}
+			}
+			opts.Alloc, err = resolveAlloc(accounts, viper.GetStringSlice("alloc"), viper.GetString("balance"))
+			if err != nil {
+				return err
+			}
+
+			b, err := genesis.SubnetEVM(opts)
+			if err != nil {
+				return err
+			}
+			out := gjson.ParseBytes(b).Get("@pretty").Raw
+			fn := viper.GetString("out")
+			if fn == "-" {
+				fmt.Print(out)
+				return nil
+			}
+			if err := utils.WriteFileBytes(fn, []byte(out)); err != nil {
+				return err
+			}
+			app.Log.Infof("Wrote %s", fn)
+			return nil
+		},
+	}
+	cmd.Flags().BoolP("interactive", "i", false, "Prompt for each value, with the flags as defaults")
+	cmd.Flags().String("out", "subnetevm-genesis.json", "File to write the genesis to, or - for stdout")
+	cmd.Flags().String("accounts", configs.AccountsFilename, "JSON file with accounts (default is the built-in accounts)")
+	cmd.Flags().Uint64("chain-id", 43112, "EVM chain ID")
+	cmd.Flags().Uint64("gas-limit", genesis.DefaultFeeConfig.GasLimit, "Block gas limit")
+	cmd.Flags().Uint64("target-block-rate", genesis.DefaultFeeConfig.TargetBlockRate, "Target seconds between blocks")
+	cmd.Flags().Uint64("min-base-fee", genesis.DefaultFeeConfig.MinBaseFee, "Minimum base fee in wei")
+	cmd.Flags().Uint64("target-gas", genesis.DefaultFeeConfig.TargetGas, "Target gas consumed per 10s")
+	cmd.Flags().Uint64("base-fee-change-denominator", genesis.DefaultFeeConfig.BaseFeeChangeDenominator, "How fast the base fee moves towards the target")
+	cmd.Flags().Uint64("min-block-gas-cost", genesis.DefaultFeeConfig.MinBlockGasCost, "Minimum block gas cost")
+	cmd.Flags().Uint64("max-block-gas-cost", genesis.DefaultFeeConfig.MaxBlockGasCost, "Maximum block gas cost")
+	cmd.Flags().Uint64("block-gas-cost-step", genesis.DefaultFeeConfig.BlockGasCostStep, "Block gas cost change per second off the target block rate")
+	cmd.Flags().Bool("allow-fee-recipients", false, "Let validators set their own fee recipient")
+	cmd.Flags().StringSlice("deployer-allow-list", []string{"owner"}, "Admins of the contract deployer allow list")
+	cmd.Flags().StringSlice("native-minter", []string{"owner"}, "Admins of the native minter")
+	cmd.Flags().StringSlice("tx-allow-list", []string{"owner"}, "Admins of the tx allow list")
+	cmd.Flags().StringSlice("fee-manager", []string{"owner"}, "Admins of the fee manager")
+	cmd.Flags().StringSlice("reward-manager", []string{"none"}, "Admins of the reward manager")
+	cmd.Flags().StringSlice("alloc", []string{}, "Accounts to fund, as name or name=amount (default all accounts)")
+	cmd.Flags().String("balance", "1000000ether", "Default balance of each --alloc account")
+	return cmd
+}
+
+// promptFlags asks for each flag on stdin, an empty answer keeps the current value
+func promptFlags(cmd *cobra.Command) error {
+	names := []string{
+		"chain-id", "gas-limit", "target-block-rate", "min-base-fee", "target-gas",
+		"base-fee-change-denominator", "min-block-gas-cost", "max-block-gas-cost",
+		"block-gas-cost-step", "allow-fee-recipients",
+	}
+	for _, p := range precompileFlags {
+		names = append(names, p.flag)
+	}
+	names = append(names, "alloc", "balance", "out")
+
+	r := bufio.NewReader(os.Stdin)
+	for _, name := range names {
+		f := cmd.Flags().Lookup(name)
+		current := f.Value.String()
+		if slice, err := cmd.Flags().GetStringSlice(name); err == nil {
+			current = strings.Join(slice, ",")
+		}
+		fmt.Fprintf(os.Stderr, "%s (%s) [%s]: ", name, f.Usage, current)
+		answer, err := r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		if answer = strings.TrimSpace(answer); answer == "" {
+			continue
+		}
+		if err := cmd.Flags().Set(name, answer); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func loadAccounts(fn string) (*gjson.Result, error) {
+	if utils.FileExists(fn) {
+		return utils.LoadJSON(fn)
+	}
+	accounts := gjson.Parse(configs.Accounts)
+	return &accounts, nil
+}
+
+// resolveAddrs turns accounts.json names or 0x addresses into addresses
+func resolveAddrs(accounts *gjson.Result, names []string) ([]common.Address, error) {
+	out := []common.Address{}
+	for _, name := range names {
+		if name == "" || name == "none" {
+			continue
+		}
+		addr := accounts.Get(name).Get("addr").String()
+		if addr == "" {
+			addr = name
+		}
+		if !common.IsHexAddress(addr) {
+			return nil, fmt.Errorf("%s is not an account in accounts.json or an address", name)
+		}
+		out = append(out, common.HexToAddress(addr))
+	}
+	return out, nil
+}
+
+func resolveAlloc(accounts *gjson.Result, entries []string, balance string) (map[common.Address]*big.Int, error) {
+	if len(entries) == 0 {
+		accounts.ForEach(func(name, _ gjson.Result) bool {
+			entries = append(entries, name.String())
+			return true
+		})
+	}
+	alloc := map[common.Address]*big.Int{}
+	for _, entry := range entries {
+		name, amount, found := strings.Cut(entry, "=")
+		if !found {
+			amount = balance
+		}
+		addrs, err := resolveAddrs(accounts, []string{name})
+		if err != nil {
+			return nil, fmt.Errorf("--alloc: %w", err)
+		}
+		wei, ok := new(big.Int).SetString(utils.ResolveAmounts([]string{amount})[0], 10)
+		if !ok {
+			return nil, fmt.Errorf("--alloc: invalid amount %q for %s", amount, name)
+		}
+		for _, addr := range addrs {
+			alloc[addr] = wei
+		}
+	}
+	return alloc, nil
+}
@@ -0,0 +1,172 @@
+package networkcmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/lasthyphen/dijetsnode/ids"
+	"github.com/lasthyphen/ecctools/cmd/nodecmd"
+	"github.com/lasthyphen/ecctools/pkg/configs"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+func newCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create num-nodes",
+		Short: "Create node dirs for a local network where every node is a genesis validator",
+		Long: `Creates num-nodes node dirs (Node1, Node2, ...) like 'ggt node prepare' does,
+then gives each node a persistent staking cert and BLS signer key, makes all of
+them initialStakers in a shared ava-genesis.json, and points each node's
+bootstrap-ips/ids at the other nodes. Unlike single nodes these run with staking
+enabled, so validator, delegator and subnet validator txs work as on a real network.
+
+Start them all with 'ggt network start'.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			num, err := strconv.Atoi(args[0])
+			if err != nil || num < 1 {
+				return fmt.Errorf("num-nodes must be a positive number, got %q", args[0])
+			}
+			if viper.GetString("ava-bin") == "" {
+				return fmt.Errorf("must supply --ava-bin flag or AVA_BIN env")
+			}
+			if exists := utils.FileExists(viper.GetString("ava-bin")); !exists {
+				return fmt.Errorf("ava-bin file does not exist: %s", viper.GetString("ava-bin"))
+			}
+			if viper.GetString("vm-bin") != "" && !utils.FileExists(viper.GetString("vm-bin")) {
+				return fmt.Errorf("vm-bin file does not exist: %s", viper.GetString("vm-bin"))
+			}
+
+			workDirs := []string{}
+			for i := 1; i <= num; i++ {
+				workDir := fmt.Sprintf("%s%d", viper.GetString("prefix"), i)
+				if _, err := os.Stat(workDir); err == nil {
+					return fmt.Errorf("%s exists, aborting", workDir)
+				}
+				workDirs = append(workDirs, workDir)
+			}
+
+			if err := createNetwork(workDirs, viper.GetString("ava-bin"), viper.GetString("vm-bin"), viper.GetString("vm-name")); err != nil {
+				return err
+			}
+			app.Log.Infof("Success! run 'ggt network start' to start all %d nodes", num)
+			return nil
+		},
+	}
+	cmd.Flags().String("prefix", "Node", "Node dirs are named prefix1, prefix2, ...")
+	cmd.Flags().String("ava-bin", "", "Location of dijetsnode binary (also AVA_BIN)")
+	cmd.Flags().String("vm-bin", "", "(optional) Location of subnetevm binary (also VM_BIN)")
+	cmd.Flags().String("vm-name", "subnetevm", "(optional) Name of vm (also VM_NAME)")
+	return cmd
+}
+
+func createNetwork(workDirs []string, avaBin string, vmBin string, vmName string) error {
+	nodeIDs := []ids.NodeID{}
+	for _, workDir := range workDirs {
+		if err := nodecmd.PrepareWorkDir(workDir, avaBin, vmBin, vmName); err != nil {
+			return err
+		}
+		nodeID, err := nodecmd.WriteStakingKeys(workDir)
+		if err != nil {
+			return err
+		}
+		app.Log.Infof("%s is %s", workDir, nodeID)
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+
+	genesis, err := networkGenesis(nodeIDs)
+	if err != nil {
+		return err
+	}
+
+	for i, workDir := range workDirs {
+		fileLocations := utils.NewFileLocations(workDir)
+		app.Log.Infof("Writing %s", fileLocations.AvaGenesisFile)
+		if err := os.WriteFile(fileLocations.AvaGenesisFile, genesis, 0644); err != nil {
+			return err
+		}
+		if err := configureStaking(workDir, workDirs, nodeIDs, i); err != nil {
+			return err
+		}
+		if err := nodecmd.WriteBashScript(workDir, true); err != nil {
+			return err
+		}
+	}
+
+	n := &network{Nodes: workDirs}
+	return n.save()
+}
+
+// networkGenesis replaces the initialStakers in the project's ava-genesis.json
+// with our nodes, keeping the reward address and delegation fee of the first one
+func networkGenesis(nodeIDs []ids.NodeID) ([]byte, error) {
+	b, err := os.ReadFile(configs.AvaGenesisFilename)
+	if err != nil {
+		return nil, err
+	}
+	genesis := gjson.ParseBytes(b)
+	rewardAddress := genesis.Get("initialStakers.0.rewardAddress").String()
+	if rewardAddress == "" {
+		rewardAddress = genesis.Get("initialStakedFunds.0").String()
+	}
+	if rewardAddress == "" {
+		return nil, fmt.Errorf("%s has no initialStakedFunds to stake with", configs.AvaGenesisFilename)
+	}
+	delegationFee := genesis.Get("initialStakers.0.delegationFee").Int()
+
+	stakers := []map[string]interface{}{}
+	for _, nodeID := range nodeIDs {
+		stakers = append(stakers, map[string]interface{}{
+			"nodeID":        nodeID.String(),
+			"rewardAddress": rewardAddress,
+			"delegationFee": delegationFee,
+		})
+	}
+	return sjson.SetBytes(b, "initialStakers", stakers)
+}
+
+// configureStaking points node i at its staking keys and at every other node as a bootstrapper
+func configureStaking(workDir string, workDirs []string, nodeIDs []ids.NodeID, i int) error {
+	configFile := utils.NewFileLocations(workDir).ConfigFile
+	// start.sh runs the node from inside its work dir
+	relative := utils.NewFileLocations("")
+
+	bootstrapIPs := []string{}
+	bootstrapIDs := []string{}
+	for j, peer := range workDirs {
+		if j == i {
+			continue
+		}
+		_, stakingPort := utils.NodePorts(peer)
+		bootstrapIPs = append(bootstrapIPs, fmt.Sprintf("127.0.0.1:%d", stakingPort))
+		bootstrapIDs = append(bootstrapIDs, nodeIDs[j].String())
+	}
+
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		return err
+	}
+	cfg := string(content)
+	settings := [][2]string{
+		{"staking-tls-cert-file", relative.StakingCertFile},
+		{"staking-tls-key-file", relative.StakingKeyFile},
+		{"staking-signer-key-file", relative.SignerKeyFile},
+		{"bootstrap-ips", strings.Join(bootstrapIPs, ",")},
+		{"bootstrap-ids", strings.Join(bootstrapIDs, ",")},
+	}
+	for _, kv := range settings {
+		cfg, err = sjson.Set(cfg, kv[0], kv[1])
+		if err != nil {
+			return err
+		}
+	}
+	app.Log.Infof("Configuring staking and bootstrap nodes in %s", configFile)
+	return os.WriteFile(configFile, []byte(cfg), 0644)
+}
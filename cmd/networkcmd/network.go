@@ -0,0 +1,57 @@
+package networkcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/lasthyphen/ecctools/pkg/application"
+	"github.com/lasthyphen/ecctools/pkg/configs"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var app *application.GoGoTools
+
+func NewCmd(injectedApp *application.GoGoTools) *cobra.Command {
+	app = injectedApp
+
+	cmd := &cobra.Command{
+		Use:   "network",
+		Short: "Create and run a local multi-validator network with staking enabled",
+		Long:  ``,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(newCreateCmd())
+	cmd.AddCommand(newStartCmd())
+	cmd.AddCommand(newStopCmd())
+	return cmd
+}
+
+// network is saved to network.json in the project dir so start/stop know which node dirs belong to it
+type network struct {
+	Nodes []string `json:"nodes"`
+}
+
+func loadNetwork() (*network, error) {
+	b, err := os.ReadFile(configs.NetworkFilename)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s, run 'ggt network create' first: %w", configs.NetworkFilename, err)
+	}
+	n := &network{}
+	if err := json.Unmarshal(b, n); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", configs.NetworkFilename, err)
+	}
+	return n, nil
+}
+
+func (n *network) save() error {
+	b, err := json.MarshalIndent(n, "", "  ")
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileBytes(configs.NetworkFilename, b)
+}
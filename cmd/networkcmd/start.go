@@ -0,0 +1,38 @@
+package networkcmd
+
+import (
+	"github.com/lasthyphen/ecctools/pkg/supervisor"
+	"github.com/spf13/cobra"
+)
+
+func newStartCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "start",
+		Short: "Start all nodes of the network in the background",
+		Long:  ``,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			n, err := loadNetwork()
+			if err != nil {
+				return err
+			}
+			for _, workDir := range n.Nodes {
+				state, err := supervisor.LoadState(workDir)
+				if err != nil {
+					return err
+				}
+				if state.IsRunning() {
+					app.Log.Infof("Node in %s is already running with pid %d", workDir, state.Pid)
+					continue
+				}
+				state, err = supervisor.StartDetached(workDir)
+				if err != nil {
+					return err
+				}
+				app.Log.Infof("Node in %s started with pid %d", workDir, state.Pid)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
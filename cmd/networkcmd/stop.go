@@ -0,0 +1,39 @@
+package networkcmd
+
+import (
+	"errors"
+	"time"
+
+	"github.com/lasthyphen/ecctools/pkg/supervisor"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newStopCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Gracefully stop all nodes of the network",
+		Long:  ``,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			n, err := loadNetwork()
+			if err != nil {
+				return err
+			}
+			for _, workDir := range n.Nodes {
+				err := supervisor.Stop(workDir, viper.GetDuration("timeout"))
+				if errors.Is(err, supervisor.ErrNotRunning) {
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				app.Log.Infof("Node in %s stopped", workDir)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Duration("timeout", 30*time.Second, "How long to wait for each node to shut down gracefully")
+	return cmd
+}
@@ -0,0 +1,73 @@
+package nodecmd
+
+import (
+	"fmt"
+
+	"github.com/lasthyphen/ecctools/pkg/nodecheck"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/lasthyphen/ecctools/pkg/vmcompat"
+	"github.com/spf13/cobra"
+)
+
+func newCheckCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check work-dir",
+		Short: "Validate the node config, chain configs and genesis in work-dir",
+		Long: `Checks node-config.json keys against dijetsnode's flags, the C, X and subnet
+chain configs against the VM config structs, and parses the genesis the way
+the node will. Unknown keys are warnings, values the node can't parse are
+errors.
+
+It also runs bin/dijetsnode and each plugin in bin/plugins with --version, and
+fails if a plugin speaks a different rpcchainvm protocol than the node, as the
+node would not load it. 'ggt node run' does all of this before starting the node.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return checkWorkDir(args[0])
+		},
+	}
+	return cmd
+}
+
+// checkWorkDir logs every problem with the node's configs and fails if the node wouldn't start
+func checkWorkDir(workDir string) error {
+	if exists := utils.DirExists(workDir); !exists {
+		return fmt.Errorf("node directory does not exist: %s", workDir)
+	}
+	problems, err := nodecheck.Check(workDir)
+	if err != nil {
+		return err
+	}
+	for _, p := range problems {
+		if p.Warning {
+			app.Log.Warn(p.String())
+		} else {
+			app.Log.Error(p.String())
+		}
+	}
+	if n := len(problems.Errors()); n > 0 {
+		return fmt.Errorf("%d problems in the configs of %s, fix them or use --skip-check", n, workDir)
+	}
+
+	report, err := vmcompat.Check(workDir)
+	if err != nil {
+		return err
+	}
+	return checkVMCompat(report)
+}
+
+// checkVMCompat logs the binaries the node can't use and fails if it would refuse a plugin
+func checkVMCompat(report *vmcompat.Report) error {
+	for _, w := range report.Warnings() {
+		app.Log.Warn(w)
+	}
+	errs := report.Errors()
+	for _, e := range errs {
+		app.Log.Error(e)
+	}
+	if len(errs) > 0 {
+		app.Log.Info("\n" + report.Table())
+		return fmt.Errorf("%d plugins are incompatible with the node binary, link matching versions or use --skip-check", len(errs))
+	}
+	return nil
+}
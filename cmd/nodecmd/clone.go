@@ -0,0 +1,193 @@
+package nodecmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lasthyphen/ecctools/pkg/supervisor"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+func newCloneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clone src-dir dst-dir",
+		Short: "Create a new node dir with the same binaries, VMs and configs as an existing one",
+		Long: `Unlike 'cp -r', the clone gets its own ports and a fresh start.sh, links that
+pointed into src-dir point into dst-dir, and the node state, supervisor log
+and snapshots are left behind.
+
+If src-dir has a staking identity (from 'ggt network create') the clone gets a
+new one, and bootstraps from src-dir as well as src-dir's bootstrap nodes.
+
+The data dir is only copied with --keep-data, which needs src-dir's node to be
+stopped.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			if err := cloneWorkDir(args[0], args[1], viper.GetBool("keep-data")); err != nil {
+				return err
+			}
+			app.Log.Infof("Success! run 'ggt node run %s' to start the node", args[1])
+			return nil
+		},
+	}
+	cmd.Flags().Bool("keep-data", false, "Also copy the data dir (except logs), so the clone starts with src-dir's chain state")
+	cmd.Flags().Int("http-port", 0, "(optional) HTTP port for the node (default is the first free port pair from 9650)")
+	cmd.Flags().Int("staking-port", 0, "(optional) Staking port for the node, requires --http-port (default is http-port+1)")
+	return cmd
+}
+
+func cloneWorkDir(src string, dst string, keepData bool) error {
+	srcFiles := utils.NewFileLocations(src)
+	if !utils.FileExists(srcFiles.ConfigFile) {
+		return fmt.Errorf("%s is not a node dir, it has no %s", src, srcFiles.ConfigFile)
+	}
+	if _, err := os.Stat(dst); err == nil {
+		return fmt.Errorf("%s exists, aborting", dst)
+	}
+	if keepData {
+		state, err := supervisor.LoadState(src)
+		if err != nil {
+			return err
+		}
+		if state.IsRunning() {
+			return fmt.Errorf("node in %s is running, stop it to copy its data", src)
+		}
+	}
+	httpPort, stakingPort, err := choosePorts(dst)
+	if err != nil {
+		return err
+	}
+	app.Log.Infof("Using http-port %d and staking-port %d", httpPort, stakingPort)
+
+	if err := mkDirs(dst); err != nil {
+		return err
+	}
+	srcDirs := utils.NewDirectoryLayout(src)
+	dstDirs := utils.NewDirectoryLayout(dst)
+	dstFiles := utils.NewFileLocations(dst)
+
+	app.Log.Infof("Copying %s to %s", srcDirs.ConfigDir, dstDirs.ConfigDir)
+	skipConfigs := func(rel string) bool { return rel == "staking" || rel == "chains-archive" }
+	if _, err := utils.CopyDir(srcDirs.ConfigDir, dstDirs.ConfigDir, skipConfigs); err != nil {
+		return err
+	}
+	if err := setPorts(dstFiles.ConfigFile, httpPort, stakingPort); err != nil {
+		return err
+	}
+	if err := cloneBinDir(src, dst); err != nil {
+		return err
+	}
+
+	staking := utils.FileExists(srcFiles.StakingCertFile)
+	if err := WriteBashScript(dst, staking); err != nil {
+		return err
+	}
+	if staking {
+		nodeID, err := WriteStakingKeys(dst)
+		if err != nil {
+			return err
+		}
+		app.Log.Infof("%s is %s", dst, nodeID)
+		if err := bootstrapFrom(dst, src); err != nil {
+			return err
+		}
+	}
+
+	if keepData {
+		app.Log.Infof("Copying %s to %s", srcDirs.DataDir, dstDirs.DataDir)
+		size, err := utils.CopyDir(srcDirs.DataDir, dstDirs.DataDir, func(rel string) bool { return rel == "logs" })
+		if err != nil {
+			return err
+		}
+		app.Log.Infof("Copied %d bytes of data", size)
+	}
+	return nil
+}
+
+// cloneBinDir copies src/bin to dst/bin. Links are made absolute, and links
+// into src (like 'ggt vm build' output) point to the copy in dst instead.
+func cloneBinDir(src string, dst string) error {
+	srcBin := utils.NewDirectoryLayout(src).BinDir
+	dstBin := utils.NewDirectoryLayout(dst).BinDir
+	srcAbs, err := filepath.Abs(src)
+	if err != nil {
+		return err
+	}
+	dstAbs, err := filepath.Abs(dst)
+	if err != nil {
+		return err
+	}
+
+	return filepath.WalkDir(srcBin, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(srcBin, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dstBin, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, os.ModePerm)
+		}
+		if d.Type()&fs.ModeSymlink == 0 {
+			_, err := utils.CopyDir(p, target, nil)
+			return err
+		}
+
+		link, err := os.Readlink(p)
+		if err != nil {
+			return err
+		}
+		if !filepath.IsAbs(link) {
+			if link, err = filepath.Abs(filepath.Join(filepath.Dir(p), link)); err != nil {
+				return err
+			}
+		}
+		if inside, err := filepath.Rel(srcAbs, link); err == nil && !strings.HasPrefix(inside, "..") {
+			link = filepath.Join(dstAbs, inside)
+		}
+		if _, err := os.Stat(link); err != nil {
+			app.Log.Warnf("%s links to %s, which does not exist", target, link)
+		}
+		return os.Symlink(link, target)
+	})
+}
+
+// bootstrapFrom adds the node in peer to the bootstrap nodes of the node in workDir
+func bootstrapFrom(workDir string, peer string) error {
+	nodeID, err := utils.NodeID(peer)
+	if err != nil {
+		return err
+	}
+	_, stakingPort := utils.NodePorts(peer)
+	configFile := utils.NewFileLocations(workDir).ConfigFile
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		return err
+	}
+
+	add := func(cfg []byte, key string, value string) ([]byte, error) {
+		values := []string{}
+		if current := gjson.GetBytes(cfg, key).String(); current != "" {
+			values = strings.Split(current, ",")
+		}
+		return sjson.SetBytes(cfg, key, strings.Join(append(values, value), ","))
+	}
+	if content, err = add(content, "bootstrap-ips", fmt.Sprintf("127.0.0.1:%d", stakingPort)); err != nil {
+		return err
+	}
+	if content, err = add(content, "bootstrap-ids", nodeID.String()); err != nil {
+		return err
+	}
+	app.Log.Infof("Bootstrapping from %s (%s) as well", peer, nodeID)
+	return os.WriteFile(configFile, content, 0644)
+}
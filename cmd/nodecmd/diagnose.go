@@ -0,0 +1,68 @@
+package nodecmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lasthyphen/ecctools/pkg/diagnose"
+	"github.com/lasthyphen/ecctools/pkg/supervisor"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newDiagnoseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diagnose work-dir",
+		Short: "Explain why the node exited or a chain didn't start, from its logs",
+		Long: `Looks through the last run in data/logs/main.log and the chain logs for known
+failures: a missing plugin or one linked under the wrong VM ID, an rpcchainvm
+protocol mismatch, a bad genesis, a port in use or a locked database.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			workDir := args[0]
+			var findings []diagnose.Finding
+			var err error
+			if chain := viper.GetString("chain"); chain != "" {
+				findings, err = diagnose.Chain(workDir, chain)
+			} else {
+				findings, err = diagnose.Node(workDir, lastStart(workDir))
+			}
+			if err != nil {
+				return err
+			}
+			if viper.GetBool("json") {
+				b, err := json.MarshalIndent(findings, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(b))
+				return nil
+			}
+			fmt.Print(diagnose.Format(workDir, findings))
+			return nil
+		},
+	}
+	cmd.Flags().String("chain", "", "Only diagnose this chain (ID or alias)")
+	cmd.Flags().Bool("json", false, "Output JSON")
+	return cmd
+}
+
+// lastStart is when the node was last started, zero if it never was
+func lastStart(workDir string) time.Time {
+	state, err := supervisor.LoadState(workDir)
+	if err != nil {
+		return time.Time{}
+	}
+	return state.StartedAt
+}
+
+// withDiagnosis appends what the logs say went wrong to err
+func withDiagnosis(workDir string, err error) error {
+	findings, derr := diagnose.Node(workDir, lastStart(workDir))
+	if derr != nil {
+		return err
+	}
+	return fmt.Errorf("%w\n%s", err, diagnose.Format(workDir, findings))
+}
@@ -1,24 +1,31 @@
 package nodecmd
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/lasthyphen/ecctools/pkg/nodeclient"
+	"github.com/lasthyphen/ecctools/pkg/utils"
 	"github.com/pkg/browser"
 	"github.com/spf13/cobra"
 )
 
 func newExplorerCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "explorer chain-name",
+		Use:   "explorer chain-name [work-dir]",
 		Short: "Launch a browser to a blockchain explorer pointed at chain-name",
 		Long:  ``,
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.RangeArgs(1, 2),
 		Run: func(cmd *cobra.Command, args []string) {
-			result, err := getInfo()
-			cobra.CheckErr(err)
-			rpc := result.Get(fmt.Sprintf("rpcs.%s", args[0])).String()
-			if rpc == "" {
-				app.Log.Fatalf("Unable to find chain-name %s in the 'rpcs' key of 'ggt node info'", args[0])
+			client := nodeclient.New(utils.ResolveNodeURL(workDirArg(args, 1)))
+			rpc := client.RPC("C")
+			if args[0] != "C" {
+				chain, err := client.FindBlockchain(context.Background(), args[0])
+				cobra.CheckErr(err)
+				if chain == nil {
+					app.Log.Fatalf("Unable to find chain-name %s in the 'rpcs' key of 'ggt node info'", args[0])
+				}
+				rpc = client.RPC(chain.ID.String())
 			}
 			url := fmt.Sprintf("http://expedition.fly.dev?rpcUrl=%s", rpc)
 			app.Log.Infof("Opening %s", url)
@@ -5,18 +5,17 @@ import (
 
 	"github.com/lasthyphen/ecctools/pkg/utils"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"github.com/tidwall/gjson"
 )
 
 func newHealthCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "health",
+		Use:   "health [work-dir]",
 		Short: "Get the health info for a node",
 		Long:  ``,
-		Args:  cobra.ExactArgs(0),
+		Args:  cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			result, err := getHealth()
+			result, err := getHealth(utils.ResolveNodeURL(workDirArg(args, 0)))
 			cobra.CheckErr(err)
 			fmt.Println(result.String())
 		},
@@ -24,8 +23,7 @@ func newHealthCmd() *cobra.Command {
 	return cmd
 }
 
-func getHealth() (*gjson.Result, error) {
-	uri := viper.GetString("node-url")
+func getHealth(uri string) (*gjson.Result, error) {
 	urlHealth := fmt.Sprintf("%s/ext/health", uri)
 
 	return utils.FetchRPCGJSON(urlHealth, "health.health", "")
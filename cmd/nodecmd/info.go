@@ -1,134 +1,48 @@
 package nodecmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 
+	"github.com/lasthyphen/ecctools/pkg/nodeclient"
 	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/lasthyphen/ecctools/pkg/vmcompat"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
 
 func newInfoCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "info",
+		Use:   "info [work-dir]",
 		Short: "Get all info for a running node in a single JSON blob",
-		Long:  ``,
-		Args:  cobra.ExactArgs(0),
+		Long: `If work-dir is supplied the node's URL is taken from its config, otherwise --node-url is used.
+
+Calls that fail (e.g. admin.getChainAliases when the admin API is disabled) are
+listed under "errors" rather than aborting the command.
+
+With a work-dir, "vmCompatibility" has the version and rpcchainvm protocol of
+the node binary and each plugin, as checked by 'ggt node check'.`,
+		Args: cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			result, err := getInfo()
+			workDir := workDirArg(args, 0)
+			// The dijetsnode API clients don't go through the retrying client, so
+			// --rpc-timeout bounds the whole command instead
+			ctx, cancel := context.WithTimeout(context.Background(), viper.GetDuration("rpc-timeout"))
+			defer cancel()
+			result, err := nodeclient.New(utils.ResolveNodeURL(workDir)).GetInfo(ctx)
 			cobra.CheckErr(err)
-			fmt.Println(result.String())
+			b, err := json.Marshal(result)
+			cobra.CheckErr(err)
+			if workDir != "" {
+				report, err := vmcompat.Check(workDir)
+				cobra.CheckErr(err)
+				b, err = sjson.SetBytes(b, "vmCompatibility", report)
+				cobra.CheckErr(err)
+			}
+			fmt.Println(string(b))
 		},
 	}
 	return cmd
 }
-
-// It's not you, Types, it's me. I think we need a break for a bit.
-func getInfo() (*gjson.Result, error) {
-	uri := viper.GetString("node-url")
-	urlInfo := fmt.Sprintf("%s/ext/info", uri)
-	urlP := fmt.Sprintf("%s/ext/bc/P", uri)
-	urlAdmin := fmt.Sprintf("%s/ext/admin", uri)
-
-	getNetworkName, err := utils.FetchRPCGJSON(urlInfo, "info.getNetworkName", "")
-	if err != nil {
-		return nil, err
-	}
-
-	getNetworkID, err := utils.FetchRPCGJSON(urlInfo, "info.getNetworkID", "")
-	if err != nil {
-		return nil, err
-	}
-
-	getNodeID, err := utils.FetchRPCGJSON(urlInfo, "info.getNodeID", "")
-	if err != nil {
-		return nil, err
-	}
-
-	getNodeVersion, err := utils.FetchRPCGJSON(urlInfo, "info.getNodeVersion", "")
-	if err != nil {
-		return nil, err
-	}
-
-	getVMs, err := utils.FetchRPCGJSON(urlInfo, "info.getVMs", "")
-	if err != nil {
-		return nil, err
-	}
-
-	getUptime, err := utils.FetchRPCGJSON(urlInfo, "info.uptime", "")
-	if err != nil {
-		return nil, err
-	}
-
-	getBlockchains, err := utils.FetchRPCGJSON(urlP, "platform.getBlockchains", "")
-	if err != nil {
-		return nil, err
-	}
-
-	getSubnets, err := utils.FetchRPCGJSON(urlP, "platform.getSubnets", "")
-	if err != nil {
-		return nil, err
-	}
-
-	stakingAssetIDs := `{}`
-	getSubnets.Get("result.subnets").ForEach(func(key, value gjson.Result) bool {
-		subnetID := value.Get("id").String()
-		stakingAsset, err := utils.FetchRPCGJSON(urlP, "platform.getStakingAssetID", fmt.Sprintf(`{"subnetID":"%s"}`, subnetID))
-		if err != nil {
-			app.Log.Infof("error retrieving stakingAssetID for subnetID: %s", subnetID)
-			return false
-		}
-		id := stakingAsset.Get("result.assetID").String()
-		if id != "" {
-			stakingAssetIDs, _ = sjson.Set(stakingAssetIDs, subnetID, id)
-		}
-		return true
-	})
-
-	aliases := `{"blockchainAliases":"AdminAPI disabled on node"}`
-	getBlockchains.Get("result.blockchains").ForEach(func(key, value gjson.Result) bool {
-		blockchainID := value.Get("id").String()
-		blockchainAliases, err := utils.FetchRPCGJSON(urlAdmin, "admin.getChainAliases", fmt.Sprintf(`{"chain":"%s"}`, blockchainID))
-		if err != nil {
-			// Maybe Admin API is disabled on this node, skip it.
-			return false
-		}
-		// If subnet didnt start for some reason, this will be blank
-		s := blockchainAliases.Get("result.aliases").String()
-		if s == "" {
-			s = `["blockchain not started, check logs"]`
-		}
-
-		aliases, _ = sjson.SetRaw(aliases, fmt.Sprintf("blockchainAliases.%s", blockchainID), s)
-		return true
-	})
-
-	url := viper.GetString("node-url")
-	rpcs := fmt.Sprintf(`{"C":"%s/ext/bc/C/rpc"}`, url)
-	getBlockchains.Get("result.blockchains").ForEach(func(key, value gjson.Result) bool {
-		if value.Get("subnetID").String() != "11111111111111111111111111111111LpoYY" {
-			blockchainID := value.Get("id").String()
-			name := value.Get("name").String()
-			rpcs, _ = sjson.Set(rpcs, name, fmt.Sprintf("%s/ext/bc/%s/rpc", url, blockchainID))
-		}
-		return true
-	})
-
-	out := "{}"
-	out, _ = sjson.Set(out, "nodeID", getNodeID.Get("result.nodeID").String())
-	out, _ = sjson.Set(out, "networkID", getNetworkID.Get("result.networkID").Int())
-	out, _ = sjson.Set(out, "networkName", getNetworkName.Get("result.networkName").String())
-	out, _ = sjson.SetRaw(out, "uptime", getUptime.Get("result").String())
-	out, _ = sjson.SetRaw(out, "getNodeVersion", getNodeVersion.Get("result").String())
-	out, _ = sjson.SetRaw(out, "getVMs", getVMs.Get("result").String())
-	out, _ = sjson.SetRaw(out, "subnets", getSubnets.Get("result.subnets").String())
-	out, _ = sjson.SetRaw(out, "stakingAssetIDs", stakingAssetIDs)
-	out, _ = sjson.SetRaw(out, "blockchains", getBlockchains.Get("result.blockchains").String())
-	out, _ = sjson.SetRaw(out, "aliases", aliases)
-	out, _ = sjson.SetRaw(out, "rpcs", rpcs)
-
-	result := gjson.Parse(out)
-	return &result, nil
-}
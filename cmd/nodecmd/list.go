@@ -0,0 +1,141 @@
+package nodecmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lasthyphen/ecctools/pkg/supervisor"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/lasthyphen/ecctools/pkg/vmcompat"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tidwall/gjson"
+)
+
+type nodeEntry struct {
+	Dir         string `json:"dir"`
+	Running     bool   `json:"running"`
+	Pid         int    `json:"pid,omitempty"`
+	HTTPPort    int    `json:"httpPort"`
+	StakingPort int    `json:"stakingPort"`
+	// Binaries with their versions
+	Versions *vmcompat.Report `json:"versions"`
+	DataSize int64            `json:"dataSize"`
+	// Chains installed by create-chain, by alias where they have one
+	Chains []string `json:"chains"`
+}
+
+func newListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list [project-dir]",
+		Short: "List the prepared node dirs in the project with their binaries, ports, chains and status",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			projectDir := workDirArg(args, 0)
+			if projectDir == "" {
+				projectDir = "."
+			}
+			nodes, err := listNodes(projectDir)
+			if err != nil {
+				return err
+			}
+			if viper.GetBool("json") {
+				b, err := json.MarshalIndent(nodes, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(b))
+				return nil
+			}
+
+			fmt.Printf("%-16s %-9s %-11s %-24s %-30s %-10s %s\n", "DIR", "STATUS", "PORTS", "DIJETSNODE", "VMS", "DATA", "CHAINS")
+			for _, n := range nodes {
+				status := "stopped"
+				if n.Running {
+					status = "running"
+				}
+				vms := []string{}
+				for _, p := range n.Versions.Plugins {
+					vms = append(vms, fmt.Sprintf("%s@%s", p.Name, p.Version))
+				}
+				fmt.Printf("%-16s %-9s %-11s %-24s %-30s %-10s %s\n", n.Dir, status, fmt.Sprintf("%d/%d", n.HTTPPort, n.StakingPort),
+					n.Versions.Node.Version, strings.Join(vms, ","), humanSize(n.DataSize), strings.Join(n.Chains, ","))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Bool("json", false, "Output JSON")
+	return cmd
+}
+
+// listNodes finds the node dirs directly inside projectDir
+func listNodes(projectDir string) ([]nodeEntry, error) {
+	entries, err := os.ReadDir(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	nodes := []nodeEntry{}
+	for _, e := range entries {
+		dir := filepath.Join(projectDir, e.Name())
+		if !e.IsDir() || !utils.FileExists(utils.NewFileLocations(dir).ConfigFile) {
+			continue
+		}
+		n := nodeEntry{Dir: e.Name(), Chains: []string{}}
+		n.HTTPPort, n.StakingPort = utils.NodePorts(dir)
+		if state, err := supervisor.LoadState(dir); err == nil && state.IsRunning() {
+			n.Running, n.Pid = true, state.Pid
+		}
+		if n.Versions, err = vmcompat.Check(dir); err != nil {
+			return nil, err
+		}
+		n.DataSize = dirSize(utils.NewDirectoryLayout(dir).DataDir)
+
+		chainIDs, err := utils.CreatedChains(dir)
+		if err != nil {
+			return nil, err
+		}
+		aliases, _ := os.ReadFile(utils.NewFileLocations(dir).ChainAliasesFile)
+		for _, id := range chainIDs {
+			name := id.String()
+			if alias := gjson.GetBytes(aliases, name+".0").String(); alias != "" {
+				name = alias
+			}
+			n.Chains = append(n.Chains, name)
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, nil
+}
+
+// dirSize is the total size of the files in dir, 0 if it doesn't exist
+func dirSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+func humanSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,250 @@
+package nodecmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lasthyphen/dijetsnode/ids"
+	"github.com/lasthyphen/ecctools/pkg/nodeclient"
+	"github.com/lasthyphen/ecctools/pkg/nodelogs"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tidwall/gjson"
+)
+
+func newLogsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logs work-dir",
+		Short: "Show the node's and chains' logs merged by time",
+		Long: `Prints data/logs/main.log and the chain logs, including the backups the log
+rotation in start.sh leaves behind (gzipped or not), merged by timestamp and
+prefixed with the log they came from. Lines without a timestamp, like stack
+traces, stay with the line before them.
+
+--chain takes main, P, C, X, a chain alias from configs/chains/aliases.json,
+a chain ID or a blockchain name known to platform.getBlockchains, and can be
+repeated. Without it every log is shown.
+
+  ggt node logs MyNode --chain mychain --level warn -f`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			workDir := args[0]
+			logsDir := filepath.Join(utils.NewDirectoryLayout(workDir).DataDir, "logs")
+
+			filter := nodelogs.Filter{}
+			if level := viper.GetString("level"); level != "" {
+				l, err := nodelogs.ParseLevel(level)
+				if err != nil {
+					return err
+				}
+				filter.MinLevel = l
+			}
+			if grep := viper.GetString("grep"); grep != "" {
+				re, err := regexp.Compile(grep)
+				if err != nil {
+					return fmt.Errorf("invalid --grep: %w", err)
+				}
+				filter.Grep = re
+			}
+			if since := viper.GetString("since"); since != "" {
+				t, err := parseSince(since)
+				if err != nil {
+					return err
+				}
+				filter.Since = t
+			}
+
+			sources, labels, err := logSources(workDir, logsDir, viper.GetStringSlice("chain"))
+			if err != nil {
+				return err
+			}
+			width := 0
+			for _, s := range sources {
+				if len(labels[s]) > width {
+					width = len(labels[s])
+				}
+			}
+			color := !viper.GetBool("no-color") && isTerminal(os.Stdout)
+			emit := func(e nodelogs.Entry) {
+				if _, ok := labels[e.Source]; !ok {
+					// A chain created while following
+					labels[e.Source] = chainLabel(workDir, e.Source)
+					sources = append(sources, e.Source)
+				}
+				if filter.Match(e) {
+					e.Source = labels[e.Source]
+					fmt.Println(nodelogs.Format(e, labelList(sources, labels), width, color))
+				}
+			}
+
+			logs := [][]nodelogs.Entry{}
+			for _, s := range sources {
+				files, err := nodelogs.Files(logsDir, s)
+				if err != nil {
+					return err
+				}
+				for _, fn := range files {
+					entries, err := nodelogs.Read(fn, s)
+					if err != nil {
+						return err
+					}
+					logs = append(logs, entries)
+				}
+			}
+			history := []nodelogs.Entry{}
+			for _, e := range nodelogs.Merge(logs...) {
+				if filter.Match(e) {
+					history = append(history, e)
+				}
+			}
+			lines := viper.GetInt("lines")
+			if lines == 0 && viper.GetBool("follow") && filter.Since.IsZero() {
+				lines = 10
+			}
+			if lines > 0 && len(history) > lines {
+				history = history[len(history)-lines:]
+			}
+			for _, e := range history {
+				emit(e)
+			}
+
+			if !viper.GetBool("follow") {
+				return nil
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			follow := sources
+			if len(viper.GetStringSlice("chain")) == 0 {
+				// Also follow the logs of chains created from now on
+				follow = nil
+			}
+			return nodelogs.Follow(ctx, logsDir, follow, viper.GetDuration("interval"), emit)
+		},
+	}
+	cmd.Flags().StringSlice("chain", nil, "Only show these logs (main, P, C, X, a chain alias, ID or name)")
+	cmd.Flags().String("level", "", "Only show entries at or above this level (debug, info, warn, error, fatal)")
+	cmd.Flags().String("grep", "", "Only show entries matching this regular expression")
+	cmd.Flags().String("since", "", "Only show entries after this time, as a duration (1h) or RFC3339")
+	cmd.Flags().IntP("lines", "n", 0, "Only show the last n matching entries (default all, 10 with --follow)")
+	cmd.Flags().BoolP("follow", "f", false, "Keep printing new entries until interrupted")
+	cmd.Flags().Duration("interval", 500*time.Millisecond, "How often to check for new entries with --follow")
+	cmd.Flags().Bool("no-color", false, "Don't color the output, even on a terminal")
+	return cmd
+}
+
+func parseSince(s string) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since %q, expected a duration like 1h or an RFC3339 time", s)
+	}
+	return t, nil
+}
+
+// logSources resolves chains to the names of their logs in logsDir, and the
+// label to print for each. The node names a chain's log after its primary
+// alias, the first one registered: P, C and X for the primary network, the
+// first alias in configs/chains/aliases.json for aliased chains, and the chain
+// ID for the rest.
+func logSources(workDir string, logsDir string, chains []string) ([]string, map[string]string, error) {
+	all, err := nodelogs.Sources(logsDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("no logs in %s: %w", logsDir, err)
+	}
+	aliases := chainAliases(workDir)
+	labels := map[string]string{}
+	if len(chains) == 0 {
+		for _, s := range all {
+			labels[s] = chainLabel(workDir, s)
+		}
+		return all, labels, nil
+	}
+
+	sources := []string{}
+	for _, chain := range chains {
+		source, err := resolveLog(workDir, logsDir, aliases, chain)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, ok := labels[source]; !ok {
+			sources = append(sources, source)
+		}
+		labels[source] = chain
+	}
+	return sources, labels, nil
+}
+
+func chainAliases(workDir string) gjson.Result {
+	if b, err := os.ReadFile(utils.NewFileLocations(workDir).ChainAliasesFile); err == nil {
+		return gjson.ParseBytes(b)
+	}
+	return gjson.Result{}
+}
+
+// chainLabel is the alias of a log named after a chain ID, or the log's name
+func chainLabel(workDir string, source string) string {
+	if a := chainAliases(workDir).Get(source + ".0").String(); a != "" {
+		return a
+	}
+	return source
+}
+
+func resolveLog(workDir string, logsDir string, aliases gjson.Result, chain string) (string, error) {
+	exists := func(name string) bool {
+		files, _ := nodelogs.Files(logsDir, name)
+		return len(files) > 0
+	}
+	if exists(chain) {
+		return chain, nil
+	}
+	id := ""
+	aliases.ForEach(func(chainID, names gjson.Result) bool {
+		for _, n := range names.Array() {
+			if n.String() == chain {
+				id = chainID.String()
+				return false
+			}
+		}
+		return true
+	})
+	if id == "" {
+		if _, err := ids.FromString(chain); err != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			b, err := nodeclient.New(utils.ResolveNodeURL(workDir)).FindBlockchain(ctx, chain)
+			if err != nil {
+				app.Log.Debugf("Unable to look up %s with platform.getBlockchains: %s", chain, err)
+			} else if b != nil {
+				id = b.ID.String()
+			}
+		}
+	}
+	if id != "" && exists(id) {
+		return id, nil
+	}
+	all, _ := nodelogs.Sources(logsDir)
+	return "", fmt.Errorf("no log for chain %s in %s, there are logs for %s", chain, logsDir, strings.Join(all, ", "))
+}
+
+func labelList(sources []string, labels map[string]string) []string {
+	out := make([]string, 0, len(sources))
+	for _, s := range sources {
+		out = append(out, labels[s])
+	}
+	return out
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -19,14 +19,36 @@ func NewCmd(injectedApp *application.GoGoTools) *cobra.Command {
 		},
 	}
 
+	cmd.AddCommand(newCheckCmd())
+	cmd.AddCommand(newCloneCmd())
 	cmd.AddCommand(newCreateUserCmd())
+	cmd.AddCommand(newDiagnoseCmd())
 	cmd.AddCommand(newHealthCmd())
 	cmd.AddCommand(newExplorerCmd())
 	cmd.AddCommand(newInfoCmd())
+	cmd.AddCommand(newListCmd())
 	cmd.AddCommand(newLoadVMsCmd())
 	cmd.AddCommand(newLogLevelCmd())
+	cmd.AddCommand(newLogsCmd())
 	cmd.AddCommand(newPrepareCmd())
 	cmd.AddCommand(newRunCmd())
 	cmd.AddCommand(newResetCmd())
+	cmd.AddCommand(newStartCmd())
+	cmd.AddCommand(newStopCmd())
+	cmd.AddCommand(newStatusCmd())
+	cmd.AddCommand(newRestartCmd())
+	cmd.AddCommand(newRollbackCmd())
+	cmd.AddCommand(newSnapshotCmd())
+	cmd.AddCommand(newUpgradeCmd())
+	cmd.AddCommand(newVMCmd())
+	cmd.AddCommand(newWaitCmd())
 	return cmd
 }
+
+// Optional work-dir args let commands target a specific node instead of --node-url
+func workDirArg(args []string, i int) string {
+	if len(args) > i {
+		return args[i]
+	}
+	return ""
+}
@@ -2,18 +2,22 @@ package nodecmd
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"html/template"
 	"os"
 	"path/filepath"
 	"reflect"
 
-	"github.com/lasthyphen/dijetsnode/ids"
+	"github.com/lasthyphen/ecctools/cmd/bincmd"
+	"github.com/lasthyphen/ecctools/pkg/binaries"
 	"github.com/lasthyphen/ecctools/pkg/configs"
 	"github.com/lasthyphen/ecctools/pkg/constants"
 	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/lasthyphen/ecctools/pkg/vmcompat"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
 
@@ -26,8 +30,11 @@ func newPrepareCmd() *cobra.Command {
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			_ = viper.BindPFlags(cmd.Flags())
+			if err := resolveBins(); err != nil {
+				return err
+			}
 			if viper.GetString("ava-bin") == "" {
-				return fmt.Errorf("must supply --ava-bin flag or AVA_BIN env")
+				return fmt.Errorf("must supply --ava-bin flag or AVA_BIN env, --ava-version, or run 'ggt bin use avalanchego <version>'")
 			}
 			if exists := utils.FileExists(viper.GetString("ava-bin")); !exists {
 				return fmt.Errorf("ava-bin file does not exist: %s", viper.GetString("ava-bin"))
@@ -41,7 +48,17 @@ func newPrepareCmd() *cobra.Command {
 				}
 			}
 
-			if err := prepareWorkDir(args[0], viper.GetString("ava-bin"), viper.GetString("vm-bin"), viper.GetString("vm-name")); err != nil {
+			if !viper.GetBool("skip-check") {
+				plugins := map[string]string{}
+				if viper.GetString("vm-bin") != "" {
+					plugins[viper.GetString("vm-name")] = viper.GetString("vm-bin")
+				}
+				if err := checkVMCompat(vmcompat.CheckBinaries(viper.GetString("ava-bin"), plugins)); err != nil {
+					return err
+				}
+			}
+
+			if err := PrepareWorkDir(args[0], viper.GetString("ava-bin"), viper.GetString("vm-bin"), viper.GetString("vm-name")); err != nil {
 				return err
 			}
 
@@ -51,24 +68,55 @@ func newPrepareCmd() *cobra.Command {
 	}
 	cmd.Flags().String("ava-bin", "", "Location of dijetsnode binary (also AVA_BIN)")
 	cmd.Flags().String("vm-bin", "", "(optional) Location of subnetevm binary (also VM_BIN)")
+	cmd.Flags().String("ava-version", "", "(optional) Use this avalanchego version from 'ggt bin', installing it if needed, instead of --ava-bin")
+	cmd.Flags().String("vm-version", "", "(optional) Use this subnetevm version from 'ggt bin', installing it if needed, instead of --vm-bin")
 	cmd.Flags().String("vm-name", "subnetevm", "(optional) Name of vm (also VM_NAME)")
+	cmd.Flags().Bool("skip-check", false, "Don't check --vm-bin speaks the same rpcchainvm protocol as --ava-bin")
+	cmd.Flags().Int("http-port", 0, "(optional) HTTP port for the node (default is the first free port pair from 9650)")
+	cmd.Flags().Int("staking-port", 0, "(optional) Staking port for the node, requires --http-port (default is http-port+1)")
 	return cmd
 }
 
-func prepareWorkDir(workDir string, avaBin string, vmBin string, vmName string) error {
+// resolveBins sets --ava-bin and --vm-bin from --ava-version and --vm-version. Without
+// either --ava-bin or --ava-version the current 'ggt bin use' avalanchego is used.
+func resolveBins() error {
+	avaVersion := viper.GetString("ava-version")
+	if viper.GetString("ava-bin") == "" || avaVersion != "" {
+		path, err := bincmd.Resolve(binaries.AvalancheGo, avaVersion)
+		if err != nil && (avaVersion != "" || !errors.Is(err, binaries.ErrNotInstalled)) {
+			return err
+		}
+		if err == nil {
+			viper.Set("ava-bin", path)
+		}
+	}
+	if v := viper.GetString("vm-version"); v != "" {
+		path, err := bincmd.Resolve(binaries.SubnetEVM, v)
+		if err != nil {
+			return err
+		}
+		viper.Set("vm-bin", path)
+	}
+	return nil
+}
+
+// PrepareWorkDir creates a new node dir with avaBin and (optionally) vmBin linked into it
+func PrepareWorkDir(workDir string, avaBin string, vmBin string, vmName string) error {
 	if _, err := os.Stat(workDir); err == nil {
 		return fmt.Errorf("%s exists, aborting", workDir)
 	}
-	err := mkDirs(workDir)
+	httpPort, stakingPort, err := choosePorts(workDir)
+	if err != nil {
+		return err
+	}
+	app.Log.Infof("Using http-port %d and staking-port %d", httpPort, stakingPort)
+
+	err = mkDirs(workDir)
 	cobra.CheckErr(err)
 
-	dirStruct := utils.NewDirectoryLayout(workDir)
 	fileLocations := utils.NewFileLocations(workDir)
 
-	bash, err := prepareBashScript(workDir)
-	cobra.CheckErr(err)
-	app.Log.Infof("Creating %s", filepath.Join(workDir, configs.BashScriptFilename))
-	err = os.WriteFile(filepath.Join(workDir, configs.BashScriptFilename), []byte(bash), constants.DefaultPerms755)
+	err = WriteBashScript(workDir, false)
 	cobra.CheckErr(err)
 
 	app.Log.Infof("Linking %s to %s", avaBin, fileLocations.AvaBinFile)
@@ -80,6 +128,9 @@ func prepareWorkDir(workDir string, avaBin string, vmBin string, vmName string)
 	app.Log.Infof("Copying %s to %s", configs.NodeConfigFilename, fileLocations.ConfigFile)
 	err = utils.CopyFile(configs.NodeConfigFilename, fileLocations.ConfigFile)
 	cobra.CheckErr(err)
+	if err := setPorts(fileLocations.ConfigFile, httpPort, stakingPort); err != nil {
+		return err
+	}
 	app.Log.Infof("Copying %s to %s", configs.CChainConfigFilename, fileLocations.CChainConfigFile)
 	err = utils.CopyFile(configs.CChainConfigFilename, fileLocations.CChainConfigFile)
 	cobra.CheckErr(err)
@@ -91,27 +142,15 @@ func prepareWorkDir(workDir string, avaBin string, vmBin string, vmName string)
 	cobra.CheckErr(err)
 
 	// Always write a vm aliases file even if empty to make avalanchego happy
-	vmAliases := "{}"
+	app.Log.Infof("Creating %s", fileLocations.VMAliasesFile)
+	err = os.WriteFile(fileLocations.VMAliasesFile, []byte("{}"), 0644)
+	cobra.CheckErr(err)
+
 	if vmBin != "" {
-		// TODO make this a fn
-		paddedBytes := [32]byte{}
-		copy(paddedBytes[:], []byte(vmName))
-		vmID, err := ids.ToID(paddedBytes[:])
-		if err != nil {
+		if err := LinkVM(workDir, vmName, vmBin); err != nil {
 			return err
 		}
-
-		fn := filepath.Join(dirStruct.PluginDir, vmID.String())
-		app.Log.Infof("Linking %s to %s", vmBin, fn)
-		if err := utils.LinkFile(vmBin, fn); err != nil {
-			return fmt.Errorf("failed linking file %w", err)
-		}
-
-		vmAliases, _ = sjson.Set("{}", vmID.String(), []string{vmName})
 	}
-	app.Log.Infof("Creating %s", fileLocations.VMAliasesFile)
-	err = os.WriteFile(fileLocations.VMAliasesFile, []byte(vmAliases), 0644)
-	cobra.CheckErr(err)
 
 	app.Log.Infof("Creating %s", fileLocations.ChainAliasesFile)
 	err = os.WriteFile(fileLocations.ChainAliasesFile, []byte("{}"), 0644)
@@ -120,15 +159,126 @@ func prepareWorkDir(workDir string, avaBin string, vmBin string, vmName string)
 	return err
 }
 
+// Give each node its own ports so several nodes in a project can run at once
+func choosePorts(workDir string) (int, int, error) {
+	httpPort := viper.GetInt("http-port")
+	stakingPort := viper.GetInt("staking-port")
+	if httpPort == 0 {
+		if stakingPort != 0 {
+			return 0, 0, fmt.Errorf("--staking-port %d requires --http-port", stakingPort)
+		}
+		return utils.AllocatePorts(filepath.Dir(filepath.Clean(workDir)))
+	}
+	if stakingPort == 0 {
+		stakingPort = httpPort + 1
+	}
+	return httpPort, stakingPort, nil
+}
+
+func setPorts(configFile string, httpPort int, stakingPort int) error {
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		return err
+	}
+	cfg, err := sjson.Set(string(content), "http-port", httpPort)
+	if err != nil {
+		return err
+	}
+	cfg, err = sjson.Set(cfg, "staking-port", stakingPort)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(configFile, []byte(cfg), 0644)
+}
+
+// LinkVM links vmBin into the node's plugin dir under the ID for vmName, replacing
+// any existing link, and registers the alias
+func LinkVM(workDir string, vmName string, vmBin string) error {
+	dirStruct := utils.NewDirectoryLayout(workDir)
+	fileLocations := utils.NewFileLocations(workDir)
+
+	vmID, err := utils.VMID(vmName)
+	if err != nil {
+		return err
+	}
+
+	fn := filepath.Join(dirStruct.PluginDir, vmID.String())
+	if _, err := os.Lstat(fn); err == nil {
+		app.Log.Infof("Replacing %s", fn)
+		if err := os.Remove(fn); err != nil {
+			return err
+		}
+	}
+	app.Log.Infof("Linking %s to %s", vmBin, fn)
+	if err := utils.LinkFile(vmBin, fn); err != nil {
+		return fmt.Errorf("failed linking file %w", err)
+	}
+
+	aliases, err := os.ReadFile(fileLocations.VMAliasesFile)
+	if err != nil {
+		return err
+	}
+	vmAliases, err := sjson.Set(string(aliases), vmID.String(), []string{vmName})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fileLocations.VMAliasesFile, []byte(vmAliases), 0644)
+}
+
+// UnlinkVM removes vmName's plugin link and alias from the node
+func UnlinkVM(workDir string, vmName string) error {
+	dirStruct := utils.NewDirectoryLayout(workDir)
+	fileLocations := utils.NewFileLocations(workDir)
+
+	vmID, err := utils.VMID(vmName)
+	if err != nil {
+		return err
+	}
+	fn := filepath.Join(dirStruct.PluginDir, vmID.String())
+	_, statErr := os.Lstat(fn)
+	aliases, err := os.ReadFile(fileLocations.VMAliasesFile)
+	if err != nil {
+		return err
+	}
+	if statErr != nil && !gjson.GetBytes(aliases, vmID.String()).Exists() {
+		return fmt.Errorf("no VM %s (%s) in %s", vmName, vmID, workDir)
+	}
+
+	if statErr == nil {
+		app.Log.Infof("Removing %s", fn)
+		if err := os.Remove(fn); err != nil {
+			return err
+		}
+	}
+	vmAliases, err := sjson.DeleteBytes(aliases, vmID.String())
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fileLocations.VMAliasesFile, vmAliases, 0644)
+}
+
 type bashCmdParams struct {
 	utils.DirectoryLayout
 	utils.FileLocations
+	Staking bool
+}
+
+// WriteBashScript writes the node's start.sh. Unless staking is true the node
+// runs with staking disabled and doesn't bootstrap from anyone.
+func WriteBashScript(workDir string, staking bool) error {
+	bash, err := prepareBashScript(staking)
+	if err != nil {
+		return err
+	}
+	fn := filepath.Join(workDir, configs.BashScriptFilename)
+	app.Log.Infof("Creating %s", fn)
+	return os.WriteFile(fn, []byte(bash), constants.DefaultPerms755)
 }
 
-func prepareBashScript(workDir string) (string, error) {
+func prepareBashScript(staking bool) (string, error) {
 	layout := utils.NewDirectoryLayout("")
 	files := utils.NewFileLocations("")
-	params := bashCmdParams{layout, files}
+	params := bashCmdParams{layout, files, staking}
 	bash := configs.StartBash
 	buf := &bytes.Buffer{}
 	t, err := template.New("").Parse(bash)
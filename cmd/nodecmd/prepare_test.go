@@ -0,0 +1,54 @@
+package nodecmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lasthyphen/ecctools/pkg/application"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestLinkUnlinkVM(t *testing.T) {
+	app = application.New()
+	workDir := t.TempDir()
+	pluginDir := utils.NewDirectoryLayout(workDir).PluginDir
+	aliasesFile := utils.NewFileLocations(workDir).VMAliasesFile
+	require.NoError(t, os.MkdirAll(pluginDir, 0755))
+	require.NoError(t, os.MkdirAll(filepath.Dir(aliasesFile), 0755))
+	require.NoError(t, os.WriteFile(aliasesFile, []byte("{}"), 0644))
+
+	vmID, err := utils.VMID("myvm")
+	require.NoError(t, err)
+	link := filepath.Join(pluginDir, vmID.String())
+	bin1, bin2 := filepath.Join(workDir, "vm1"), filepath.Join(workDir, "vm2")
+	require.NoError(t, os.WriteFile(bin1, nil, 0755))
+	require.NoError(t, os.WriteFile(bin2, nil, 0755))
+
+	require.ErrorContains(t, UnlinkVM(workDir, "myvm"), "no VM myvm")
+
+	require.NoError(t, LinkVM(workDir, "myvm", bin1))
+	target, err := os.Readlink(link)
+	require.NoError(t, err)
+	require.Equal(t, bin1, target)
+
+	// Replacing the link also replaces a stale alias
+	require.NoError(t, os.WriteFile(aliasesFile, []byte(`{"`+vmID.String()+`":["stale"]}`), 0644))
+	require.NoError(t, LinkVM(workDir, "myvm", bin2))
+	target, err = os.Readlink(link)
+	require.NoError(t, err)
+	require.Equal(t, bin2, target)
+	aliases, err := os.ReadFile(aliasesFile)
+	require.NoError(t, err)
+	require.Equal(t, `["myvm"]`, gjson.GetBytes(aliases, vmID.String()).Raw)
+
+	require.NoError(t, UnlinkVM(workDir, "myvm"))
+	_, err = os.Lstat(link)
+	require.True(t, os.IsNotExist(err))
+	aliases, err = os.ReadFile(aliasesFile)
+	require.NoError(t, err)
+	require.False(t, gjson.GetBytes(aliases, vmID.String()).Exists())
+	require.ErrorContains(t, UnlinkVM(workDir, "myvm"), "no VM myvm")
+}
@@ -1,31 +1,192 @@
 package nodecmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/lasthyphen/dijetsnode/ids"
+	"github.com/lasthyphen/ecctools/cmd/walletcmd"
 	"github.com/lasthyphen/ecctools/pkg/constants"
+	"github.com/lasthyphen/ecctools/pkg/supervisor"
+	"github.com/lasthyphen/ecctools/pkg/utils"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// What reset does with the configs of chains that no longer exist
+const (
+	chainsArchive  = "archive"
+	chainsPrune    = "prune"
+	chainsKeep     = "keep"
+	chainsRecreate = "recreate"
 )
 
 func newResetCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "reset work-dir",
-		Short: "Nuke the data directory of the node",
-		Long:  ``,
-		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			workDir := args[0]
-			err := os.RemoveAll(filepath.Join(workDir, "data"))
-			if err != nil {
-				app.Log.Fatal("unable to delete data directory")
-			}
-			err = os.Mkdir(filepath.Join(workDir, "data"), constants.DefaultPerms755)
-			if err != nil {
-				app.Log.Fatal("unable to recreate data directory")
-			}
-			// TODO use .pid file to bounce node?
+		Short: "Nuke the data directory of the node, keeping its chain configs consistent",
+		Long: `Deletes the node's data dir, stopping the node first if it is running and
+starting it again afterwards.
+
+The subnets and chains created on the node are gone after that, so their chain
+configs and aliases in configs/chains are moved to configs/chains-archive/<time>
+and they are removed from track-subnets (see --chains). With --chains recreate
+the subnets and chains are read from the running node first, and created again
+with the same owners, names, VMs, genesis and configs, so names keep working.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			return reset(args[0], viper.GetString("chains"))
 		},
 	}
+	cmd.Flags().String("chains", chainsArchive, "What to do with the configs of created chains: archive, prune, keep or recreate")
+	cmd.Flags().Bool("no-restart", false, "Don't start the node again if it was running")
+	cmd.Flags().Duration("timeout", 30*time.Second, "How long to wait for a graceful shutdown")
+	cmd.Flags().Duration("health-timeout", 2*time.Minute, "How long to wait for the node to be healthy after starting it again")
+	cmd.Flags().String("pk", walletcmd.DefaultPrivateKey, "Private key used to recreate subnets and chains")
 	return cmd
 }
+
+func reset(workDir string, mode string) error {
+	if !utils.DirExists(workDir) {
+		return fmt.Errorf("node directory does not exist: %s", workDir)
+	}
+	switch mode {
+	case chainsArchive, chainsPrune, chainsKeep, chainsRecreate:
+	default:
+		return fmt.Errorf("invalid --chains %q, expected archive, prune, keep or recreate", mode)
+	}
+	state, err := supervisor.LoadState(workDir)
+	if err != nil {
+		return err
+	}
+	running := state.IsRunning()
+	chainIDs, err := utils.CreatedChains(workDir)
+	if err != nil {
+		return err
+	}
+
+	// Chain txs only exist until the data is gone
+	records := []walletcmd.ChainRecord{}
+	tracked := trackedSubnets(workDir)
+	if mode == chainsRecreate {
+		if !running {
+			return errors.New("--chains recreate reads the chains from the node, start it first")
+		}
+		uri := utils.ResolveNodeURL(workDir)
+		for _, id := range chainIDs {
+			r, err := walletcmd.GetChainRecord(context.Background(), uri, id)
+			if err != nil {
+				app.Log.Warnf("Not recreating %s: %s", id, err)
+				continue
+			}
+			records = append(records, *r)
+		}
+	}
+	key, err := walletcmd.DecodePrivateKey(viper.GetString("pk"))
+	if err != nil && len(records) > 0 {
+		return err
+	}
+
+	if running {
+		app.Log.Infof("Stopping node in %s...", workDir)
+		if err := supervisor.Stop(workDir, viper.GetDuration("timeout")); err != nil && !errors.Is(err, supervisor.ErrNotRunning) {
+			return err
+		}
+	}
+	dataDir := utils.NewDirectoryLayout(workDir).DataDir
+	if err := os.RemoveAll(dataDir); err != nil {
+		return fmt.Errorf("unable to delete data directory: %w", err)
+	}
+	if err := os.Mkdir(dataDir, constants.DefaultPerms755); err != nil {
+		return fmt.Errorf("unable to recreate data directory: %w", err)
+	}
+	app.Log.Infof("Deleted %s", dataDir)
+
+	archiveDir := ""
+	if mode != chainsKeep && len(chainIDs) > 0 {
+		if mode != chainsPrune {
+			archiveDir = filepath.Join(workDir, "configs", "chains-archive", time.Now().UTC().Format("20060102-150405"))
+		}
+		if err := utils.RemoveChainConfigs(workDir, chainIDs, archiveDir); err != nil {
+			return err
+		}
+		if archiveDir == "" {
+			app.Log.Infof("Deleted the configs of %d chains", len(chainIDs))
+		} else {
+			app.Log.Infof("Moved the configs of %d chains to %s", len(chainIDs), archiveDir)
+		}
+	}
+	if mode != chainsKeep && len(tracked) > 0 {
+		if err := untrackSubnets(workDir); err != nil {
+			return err
+		}
+	}
+
+	if !running || (viper.GetBool("no-restart") && mode != chainsRecreate) {
+		return nil
+	}
+	if err := startAndWaitHealthy(workDir, viper.GetDuration("health-timeout")); err != nil {
+		return err
+	}
+	app.Log.Infof("Node in %s is healthy", workDir)
+	if len(records) == 0 {
+		return nil
+	}
+
+	configFiles := map[ids.ID]string{}
+	for _, r := range records {
+		if fn := filepath.Join(archiveDir, r.ID.String(), "config.json"); utils.FileExists(fn) {
+			configFiles[r.ID] = fn
+		}
+	}
+	if _, err := walletcmd.RecreateChains(workDir, key, records, configFiles, tracked); err != nil {
+		return err
+	}
+	// The node only starts the chains of subnets it tracks after a restart
+	app.Log.Infof("Restarting node in %s to start the recreated chains...", workDir)
+	if err := supervisor.Stop(workDir, viper.GetDuration("timeout")); err != nil && !errors.Is(err, supervisor.ErrNotRunning) {
+		return err
+	}
+	if err := startAndWaitHealthy(workDir, viper.GetDuration("health-timeout")); err != nil {
+		return err
+	}
+	app.Log.Infof("Node in %s is healthy", workDir)
+	return nil
+}
+
+// trackedSubnets are the subnets in track-subnets in the node's node-config.json
+func trackedSubnets(workDir string) map[ids.ID]bool {
+	out := map[ids.ID]bool{}
+	b, err := os.ReadFile(utils.NewFileLocations(workDir).ConfigFile)
+	if err != nil {
+		return out
+	}
+	for _, s := range strings.Split(gjson.GetBytes(b, "track-subnets").String(), ",") {
+		if id, err := ids.FromString(strings.TrimSpace(s)); err == nil {
+			out[id] = true
+		}
+	}
+	return out
+}
+
+// untrackSubnets removes track-subnets from node-config.json, after a reset none of them exist
+func untrackSubnets(workDir string) error {
+	fn := utils.NewFileLocations(workDir).ConfigFile
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return err
+	}
+	app.Log.Infof("Removing track-subnets %s from %s", gjson.GetBytes(b, "track-subnets").String(), fn)
+	if b, err = sjson.DeleteBytes(b, "track-subnets"); err != nil {
+		return err
+	}
+	return os.WriteFile(fn, b, 0644)
+}
@@ -0,0 +1,29 @@
+package nodecmd
+
+import (
+	"time"
+
+	"github.com/lasthyphen/ecctools/pkg/supervisor"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newRestartCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restart work-dir",
+		Short: "Restart a node, or start it in the background if it is not running",
+		Long:  ``,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			state, err := supervisor.Restart(args[0], viper.GetDuration("timeout"))
+			if err != nil {
+				return err
+			}
+			app.Log.Infof("Node in %s running with pid %d", args[0], state.Pid)
+			return nil
+		},
+	}
+	cmd.Flags().Duration("timeout", 30*time.Second, "How long to wait for a graceful shutdown")
+	return cmd
+}
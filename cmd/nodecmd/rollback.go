@@ -0,0 +1,81 @@
+package nodecmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lasthyphen/ecctools/pkg/snapshot"
+	"github.com/lasthyphen/ecctools/pkg/supervisor"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newRollbackCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rollback work-dir [snapshot]",
+		Short: "Undo 'ggt node upgrade', restoring the previous binaries and data",
+		Long: `Stops the node, restores the data dir and binaries from the snapshot taken by
+the last 'ggt node upgrade' (or the named snapshot), and starts the node again
+if it was running.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			workDir := args[0]
+			if !utils.DirExists(workDir) {
+				return fmt.Errorf("node directory does not exist: %s", workDir)
+			}
+			name := workDirArg(args, 1)
+			if name == "" {
+				var err error
+				if name, err = lastUpgradeSnapshot(workDir); err != nil {
+					return err
+				}
+			}
+			state, err := supervisor.LoadState(workDir)
+			if err != nil {
+				return err
+			}
+			return rollback(workDir, name, state.IsRunning())
+		},
+	}
+	cmd.Flags().Duration("timeout", 30*time.Second, "How long to wait for a graceful shutdown")
+	cmd.Flags().Duration("health-timeout", 2*time.Minute, "How long to wait for the node to be healthy after restarting it")
+	return cmd
+}
+
+func lastUpgradeSnapshot(workDir string) (string, error) {
+	snaps, err := snapshot.List(workDir)
+	if err != nil {
+		return "", err
+	}
+	for i := len(snaps) - 1; i >= 0; i-- {
+		if strings.HasPrefix(snaps[i].Name, upgradeSnapshotPrefix) {
+			return snaps[i].Name, nil
+		}
+	}
+	return "", fmt.Errorf("no upgrade snapshots in %s", snapshot.Dir(workDir))
+}
+
+// rollback stops the node, restores the snapshot's data and binaries, and starts the node again if start is set
+func rollback(workDir string, name string, start bool) error {
+	if err := supervisor.Stop(workDir, viper.GetDuration("timeout")); err != nil && !errors.Is(err, supervisor.ErrNotRunning) {
+		return err
+	}
+	app.Log.Infof("Restoring snapshot %s...", name)
+	snap, err := snapshot.Restore(workDir, name, true)
+	if err != nil {
+		return err
+	}
+	app.Log.Infof("Restored data and binaries (%s) from %s", snap.AvaBin, snap.CreatedAt.Local().Format(time.RFC3339))
+	if !start {
+		return nil
+	}
+	if err := startAndWaitHealthy(workDir, viper.GetDuration("health-timeout")); err != nil {
+		return err
+	}
+	app.Log.Infof("Node in %s is healthy", workDir)
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	gocmd "github.com/go-cmd/cmd"
 	"github.com/lasthyphen/ecctools/pkg/configs"
 	"github.com/lasthyphen/ecctools/pkg/constants"
+	"github.com/lasthyphen/ecctools/pkg/supervisor"
 	"github.com/lasthyphen/ecctools/pkg/utils"
 	"github.com/radovskyb/watcher"
 	"github.com/spf13/cobra"
@@ -25,36 +26,52 @@ func newRunCmd() *cobra.Command {
 		Long:  ``,
 		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			workDir := args[0]
 			_ = viper.BindPFlags(cmd.Flags())
-
-			exitIfRunning()
-
-			// Truncate instead of delete so log tailing is not affected
-			if viper.GetBool("clear-logs") {
-				logsPath := filepath.Join(workDir, "data", "logs")
-				logFiles, err := utils.FilePathWalk(logsPath, "log")
-				cobra.CheckErr(err)
-				for _, f := range logFiles {
-					err := utils.Truncate(f, constants.DefaultPerms755)
-					cobra.CheckErr(err)
-				}
-				cobra.CheckErr(err)
-			}
-
-			startCmd := filepath.Join(workDir, configs.BashScriptFilename)
-			_ = runNodeAndWait(workDir, startCmd)
+			runForeground(args[0])
 		},
 	}
 	cmd.Flags().Bool("clear-logs", false, "Delete logs/* before starting node")
 	cmd.Flags().Bool("watch", false, "(Experimental!) Watch data/bin and restart on any file changes")
+	_ = cmd.Flags().MarkDeprecated("watch", "use 'ggt vm build --watch' to rebuild and reload plugins when their sources change")
+	cmd.Flags().Bool("skip-check", false, "Start the node even if 'ggt node check' finds problems")
 
 	return cmd
 }
 
-func exitIfRunning() {
-	if utils.FileExists(".pid") {
-		app.Log.Fatalf(".pid file exists, is another node already running? Delete .pid and try again.")
+func runForeground(workDir string) {
+	if exists := utils.DirExists(workDir); !exists {
+		app.Log.Fatalf("node directory does not exist: %s", workDir)
+	}
+
+	exitIfRunning(workDir)
+
+	if !viper.GetBool("skip-check") {
+		if err := checkWorkDir(workDir); err != nil {
+			app.Log.Fatal(err)
+		}
+	}
+
+	// Truncate instead of delete so log tailing is not affected
+	if viper.GetBool("clear-logs") {
+		logsPath := filepath.Join(workDir, "data", "logs")
+		logFiles, err := utils.FilePathWalk(logsPath, "log")
+		cobra.CheckErr(err)
+		for _, f := range logFiles {
+			err := utils.Truncate(f, constants.DefaultPerms755)
+			cobra.CheckErr(err)
+		}
+		cobra.CheckErr(err)
+	}
+
+	startCmd := filepath.Join(workDir, configs.BashScriptFilename)
+	_ = runNodeAndWait(workDir, startCmd)
+}
+
+func exitIfRunning(workDir string) {
+	state, err := supervisor.LoadState(workDir)
+	cobra.CheckErr(err)
+	if state.IsRunning() {
+		app.Log.Fatalf("node in %s is already running with pid %d, use 'ggt node stop %s' first", workDir, state.Pid, workDir)
 	}
 }
 
@@ -94,18 +111,27 @@ func runNode(workDir string, cmd string) error {
 	// Ctl-C wil stop the node
 	cSigTerm := make(chan os.Signal, 1)
 	signal.Notify(cSigTerm, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(cSigTerm)
 
 	// USR1 can be sent to restart the node, use https://github.com/watchexec/watchexec
 	cSigUser1 := make(chan os.Signal, 1)
 	signal.Notify(cSigUser1, syscall.SIGUSR1)
+	defer signal.Stop(cSigUser1)
 
 	// Start the node
 	app.Log.Info("Starting node...")
+	state := supervisor.NewState(workDir)
 	statusChan := envCmd.Start()
 	doneChan := envCmd.Done()
 
-	app.Log.Infof("Avalanche node listening on http://0.0.0.0:9650")
-	app.Log.Infof("(Send USR1 to PID %d to restart the node)", os.Getpid())
+	// Record the node's pid in the work dir so other commands can stop/restart us
+	state.Pid = waitForPid(envCmd)
+	if err := state.Save(workDir); err != nil {
+		app.Log.Warnf("unable to write node state: %s", err)
+	}
+
+	app.Log.Infof("Avalanche node listening on %s", utils.NodeURL(workDir))
+	app.Log.Infof("(Run 'ggt node restart %s' or send USR1 to PID %d to restart the node)", workDir, os.Getpid())
 	app.Log.Infof("(If you have problems you can always run '%s/start.sh' directly)", workDir)
 	// TODO dont show the below if they have already created a subnet
 	app.Log.Infof("In another terminal, run this command to create a subnetEVM")
@@ -146,10 +172,6 @@ func runNode(workDir string, cmd string) error {
 		// }
 	}
 
-	// Write a .pid so other commands can restart us with a USR1 if necessary
-	_ = utils.WriteFileBytes(".pid", []byte(fmt.Sprintf("%d", os.Getpid())))
-	defer func() { os.Remove(".pid") }()
-
 	for {
 		select {
 		case <-cSigUser1:
@@ -171,13 +193,17 @@ func runNode(workDir string, cmd string) error {
 		case <-doneChan:
 			app.Log.Debug("Recvd donechan")
 			app.Log.Debugf("%+v", finalStatus)
+			state.MarkStopped(finalStatus.Exit)
+			if err := state.Save(workDir); err != nil {
+				app.Log.Warnf("unable to write node state: %s", err)
+			}
 			if shouldRestart {
 				app.Log.Info("Restarting node...")
 				time.Sleep(time.Second * 5)
 				return nil
 			} else {
 				if finalStatus.Exit > 0 {
-					app.Log.Infof("program exited with code: %d (check logs for more info)", finalStatus.Exit)
+					app.Log.Error(withDiagnosis(workDir, fmt.Errorf("node exited with code %d", finalStatus.Exit)))
 				}
 				// Normal exit, but we have to return err so we break out of loop and dont restart
 				return fmt.Errorf("program exited")
@@ -185,3 +211,14 @@ func runNode(workDir string, cmd string) error {
 		}
 	}
 }
+
+// gocmd fills in the pid asynchronously after Start
+func waitForPid(envCmd *gocmd.Cmd) int {
+	for i := 0; i < 100; i++ {
+		if pid := envCmd.Status().PID; pid != 0 {
+			return pid
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return 0
+}
@@ -0,0 +1,62 @@
+package nodecmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lasthyphen/ecctools/pkg/supervisor"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newSnapshotCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Save, list, restore and delete snapshots of a node's chain state",
+		Long: `A snapshot is a copy of the node's data dir and the chain configs and aliases
+'ggt wallet create-chain' wrote, plus the binaries the node ran and the chain
+heights at the time. Snapshots are kept in work-dir/snapshots.
+
+  ggt node snapshot save MyNode genesis --stop
+  ggt node snapshot restore MyNode genesis --stop`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(newSnapshotSaveCmd())
+	cmd.AddCommand(newSnapshotListCmd())
+	cmd.AddCommand(newSnapshotRestoreCmd())
+	cmd.AddCommand(newSnapshotDeleteCmd())
+	return cmd
+}
+
+// stopForSnapshot stops the node if it is running and --stop is set, and
+// returns whether it was running so it can be started again
+func stopForSnapshot(workDir string) (bool, error) {
+	if !utils.DirExists(workDir) {
+		return false, fmt.Errorf("node directory does not exist: %s", workDir)
+	}
+	state, err := supervisor.LoadState(workDir)
+	if err != nil {
+		return false, err
+	}
+	if !state.IsRunning() {
+		return false, nil
+	}
+	if !viper.GetBool("stop") {
+		return false, errors.New("node is running, stop it first or use --stop to stop it and start it again afterwards")
+	}
+	if err := supervisor.Stop(workDir, viper.GetDuration("timeout")); err != nil && !errors.Is(err, supervisor.ErrNotRunning) {
+		return false, err
+	}
+	return true, nil
+}
+
+func addSnapshotStopFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("stop", false, "Stop the node if it is running, and start it again afterwards")
+	cmd.Flags().Duration("timeout", 30*time.Second, "How long to wait for a graceful shutdown")
+	cmd.Flags().Duration("health-timeout", 2*time.Minute, "How long to wait for the node to be healthy after starting it again")
+}
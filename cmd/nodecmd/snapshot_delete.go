@@ -0,0 +1,23 @@
+package nodecmd
+
+import (
+	"github.com/lasthyphen/ecctools/pkg/snapshot"
+	"github.com/spf13/cobra"
+)
+
+func newSnapshotDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete work-dir name...",
+		Short: "Delete snapshots",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, name := range args[1:] {
+				if err := snapshot.Delete(args[0], name); err != nil {
+					return err
+				}
+				app.Log.Infof("Deleted snapshot %s", name)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,57 @@
+package nodecmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/lasthyphen/ecctools/pkg/snapshot"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newSnapshotListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list work-dir",
+		Short: "List the node's snapshots, oldest first",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			snaps, err := snapshot.List(args[0])
+			if err != nil {
+				return err
+			}
+			if viper.GetBool("json") {
+				b, err := json.MarshalIndent(snaps, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(b))
+				return nil
+			}
+
+			fmt.Printf("%-28s %-20s %-24s %-12s %-30s %s\n", "NAME", "CREATED", "NODE", "SIZE", "HEIGHTS", "REASON")
+			for _, s := range snaps {
+				node := "?"
+				if s.Versions != nil && s.Versions.Node.Version != "" {
+					node = s.Versions.Node.Version
+				}
+				heights := []string{}
+				for _, c := range s.Chains {
+					if c.Height == nil {
+						continue
+					}
+					name := c.ID
+					if len(c.Aliases) > 0 {
+						name = c.Aliases[0]
+					}
+					heights = append(heights, fmt.Sprintf("%s=%d", name, *c.Height))
+				}
+				fmt.Printf("%-28s %-20s %-24s %-12d %-30s %s\n", s.Name, s.CreatedAt.Local().Format("2006-01-02 15:04:05"), node, s.Size, strings.Join(heights, ","), s.Reason)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Bool("json", false, "Output JSON, with each snapshot's chains and binaries")
+	return cmd
+}
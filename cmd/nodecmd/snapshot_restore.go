@@ -0,0 +1,90 @@
+package nodecmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lasthyphen/ecctools/pkg/snapshot"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newSnapshotRestoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore work-dir name",
+		Short: "Replace the node's data dir and chain configs with a snapshot's",
+		Long: `Keeps the node's current binaries, unless --bins is given, and refuses if they
+can't run the snapshot's data: a different database version, or a VM the
+snapshot's chains need that the node has no plugin for.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			workDir, name := args[0], args[1]
+			snap, err := snapshot.Get(workDir, name)
+			if err != nil {
+				return err
+			}
+			if !viper.GetBool("force") {
+				if err := checkSnapshot(workDir, snap, viper.GetBool("bins")); err != nil {
+					return err
+				}
+			}
+			wasRunning, err := stopForSnapshot(workDir)
+			if err != nil {
+				return err
+			}
+
+			app.Log.Infof("Restoring snapshot %s...", name)
+			if _, err := snapshot.Restore(workDir, name, viper.GetBool("bins")); err != nil {
+				return err
+			}
+			app.Log.Infof("Restored data and %d chains from %s", len(snap.Chains), snap.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+
+			if wasRunning {
+				if err := startAndWaitHealthy(workDir, viper.GetDuration("health-timeout")); err != nil {
+					return err
+				}
+				app.Log.Infof("Node in %s is healthy", workDir)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Bool("bins", false, "Also relink the binaries and VM aliases the snapshot was taken with")
+	cmd.Flags().Bool("force", false, "Restore even if the binaries look incompatible with the snapshot")
+	addSnapshotStopFlags(cmd)
+	return cmd
+}
+
+// checkSnapshot fails if the binaries the node will run after restoring can't run the snapshot's data
+func checkSnapshot(workDir string, snap *snapshot.Snapshot, bins bool) error {
+	if bins {
+		missing := []string{}
+		for _, target := range append([]string{snap.AvaBin}, mapValues(snap.Plugins)...) {
+			if target != "" && !utils.FileExists(target) {
+				missing = append(missing, target)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("binaries of snapshot %s no longer exist: %s, use --force to restore anyway", snap.Name, strings.Join(missing, ", "))
+		}
+		return nil
+	}
+	problems, err := snap.Check(workDir)
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("the node's binaries can't run snapshot %s:\n  %s\nuse --bins to restore the snapshot's binaries too, or --force to restore anyway",
+			snap.Name, strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+func mapValues(m map[string]string) []string {
+	out := make([]string, 0, len(m))
+	for _, v := range m {
+		out = append(out, v)
+	}
+	return out
+}
@@ -0,0 +1,72 @@
+package nodecmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/lasthyphen/ecctools/pkg/nodeclient"
+	"github.com/lasthyphen/ecctools/pkg/snapshot"
+	"github.com/lasthyphen/ecctools/pkg/supervisor"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newSnapshotSaveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "save work-dir name",
+		Short: "Snapshot the node's data dir, chain configs and binaries",
+		Long: `The node must be stopped, copying a live database gives a corrupt snapshot.
+With --stop a running node is asked for its chain heights, stopped, snapshotted
+and started again.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			workDir, name := args[0], args[1]
+			opts := snapshot.Options{Reason: viper.GetString("reason")}
+
+			// Heights can only be asked for before stopping
+			if state, err := supervisor.LoadState(workDir); err == nil && state.IsRunning() && viper.GetBool("stop") {
+				opts.Heights = chainHeights(workDir)
+			}
+			wasRunning, err := stopForSnapshot(workDir)
+			if err != nil {
+				return err
+			}
+
+			app.Log.Infof("Saving snapshot %s...", name)
+			snap, err := snapshot.Save(workDir, name, opts)
+			if err != nil {
+				return err
+			}
+			app.Log.Infof("Saved %d bytes of data and %d chains to %s", snap.Size, len(snap.Chains), snapshot.Dir(workDir))
+
+			if wasRunning {
+				if err := startAndWaitHealthy(workDir, viper.GetDuration("health-timeout")); err != nil {
+					return err
+				}
+				app.Log.Infof("Node in %s is healthy", workDir)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().String("reason", "", "Note to keep with the snapshot")
+	addSnapshotStopFlags(cmd)
+	return cmd
+}
+
+// chainHeights asks the running node for the height of P and every chain in its configs
+func chainHeights(workDir string) map[string]uint64 {
+	chains, err := snapshot.Chains(workDir, nil)
+	if err != nil {
+		app.Log.Warnf("Unable to list chains: %s", err)
+		return nil
+	}
+	ids := []string{}
+	for _, c := range chains {
+		ids = append(ids, c.ID)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return nodeclient.New(utils.NodeURL(workDir)).Heights(ctx, ids)
+}
@@ -0,0 +1,34 @@
+package nodecmd
+
+import (
+	"github.com/lasthyphen/dijetsnode/ids"
+	"github.com/lasthyphen/dijetsnode/staking"
+	"github.com/lasthyphen/dijetsnode/utils/crypto/bls"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+)
+
+// WriteStakingKeys generates the node's TLS cert (which determines its NodeID) and BLS key
+func WriteStakingKeys(workDir string) (ids.NodeID, error) {
+	fileLocations := utils.NewFileLocations(workDir)
+
+	certBytes, keyBytes, err := staking.NewCertAndKeyBytes()
+	if err != nil {
+		return ids.EmptyNodeID, err
+	}
+	if err := utils.WriteFileBytes(fileLocations.StakingCertFile, certBytes); err != nil {
+		return ids.EmptyNodeID, err
+	}
+	if err := utils.WriteFileBytes(fileLocations.StakingKeyFile, keyBytes); err != nil {
+		return ids.EmptyNodeID, err
+	}
+
+	signer, err := bls.NewSecretKey()
+	if err != nil {
+		return ids.EmptyNodeID, err
+	}
+	if err := utils.WriteFileBytes(fileLocations.SignerKeyFile, bls.SecretKeyToBytes(signer)); err != nil {
+		return ids.EmptyNodeID, err
+	}
+
+	return utils.NodeID(workDir)
+}
@@ -0,0 +1,56 @@
+package nodecmd
+
+import (
+	"fmt"
+
+	"github.com/lasthyphen/ecctools/pkg/supervisor"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newStartCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "start work-dir",
+		Short: "Start avalanchego from a previously prepared directory, optionally in the background",
+		Long: `Without -d this is the same as 'ggt node run'. With -d the node is supervised
+by a background ggt process which logs to ggt.log in the work-dir. Use
+'ggt node status|stop|restart' to manage it.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			workDir := args[0]
+			_ = viper.BindPFlags(cmd.Flags())
+
+			if !viper.GetBool("detach") {
+				runForeground(workDir)
+				return nil
+			}
+
+			if exists := utils.DirExists(workDir); !exists {
+				return fmt.Errorf("node directory does not exist: %s", workDir)
+			}
+
+			// Check here so problems show up in the terminal rather than ggt.log
+			if !viper.GetBool("skip-check") {
+				if err := checkWorkDir(workDir); err != nil {
+					return err
+				}
+			}
+			extraArgs := []string{"--skip-check"}
+			if viper.GetBool("clear-logs") {
+				extraArgs = append(extraArgs, "--clear-logs")
+			}
+			state, err := supervisor.StartDetached(workDir, extraArgs...)
+			if err != nil {
+				return withDiagnosis(workDir, err)
+			}
+			app.Log.Infof("Node started in background with pid %d", state.Pid)
+			app.Log.Infof("Supervisor logs: %s", utils.NewFileLocations(workDir).SupervisorLog)
+			return nil
+		},
+	}
+	cmd.Flags().BoolP("detach", "d", false, "Run the node in the background")
+	cmd.Flags().Bool("clear-logs", false, "Delete logs/* before starting node")
+	cmd.Flags().Bool("skip-check", false, "Start the node even if 'ggt node check' finds problems")
+	return cmd
+}
@@ -0,0 +1,31 @@
+package nodecmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/lasthyphen/ecctools/pkg/supervisor"
+	"github.com/spf13/cobra"
+)
+
+func newStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status work-dir",
+		Short: "Show whether the node is running, its pid, binaries and last exit status",
+		Long:  ``,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			status, err := supervisor.GetStatus(args[0])
+			if err != nil {
+				return err
+			}
+			b, err := json.MarshalIndent(status, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		},
+	}
+	return cmd
+}
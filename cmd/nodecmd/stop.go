@@ -0,0 +1,34 @@
+package nodecmd
+
+import (
+	"errors"
+	"time"
+
+	"github.com/lasthyphen/ecctools/pkg/supervisor"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newStopCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stop work-dir",
+		Short: "Gracefully stop a running node, killing it after --timeout",
+		Long:  ``,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			err := supervisor.Stop(args[0], viper.GetDuration("timeout"))
+			if errors.Is(err, supervisor.ErrNotRunning) {
+				app.Log.Infof("Node in %s is not running", args[0])
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			app.Log.Infof("Node in %s stopped", args[0])
+			return nil
+		},
+	}
+	cmd.Flags().Duration("timeout", 30*time.Second, "How long to wait for a graceful shutdown")
+	return cmd
+}
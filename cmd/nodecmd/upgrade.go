@@ -0,0 +1,170 @@
+package nodecmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lasthyphen/ecctools/cmd/bincmd"
+	"github.com/lasthyphen/ecctools/pkg/binaries"
+	"github.com/lasthyphen/ecctools/pkg/nodeclient"
+	"github.com/lasthyphen/ecctools/pkg/snapshot"
+	"github.com/lasthyphen/ecctools/pkg/supervisor"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/lasthyphen/ecctools/pkg/vmcompat"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Snapshots taken by upgrade are named upgradeSnapshotPrefix + timestamp
+const upgradeSnapshotPrefix = "upgrade-"
+
+func newUpgradeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upgrade work-dir",
+		Short: "Swap a node's binaries in place, keeping its data",
+		Long: `Stops the node, snapshots its data dir and current binaries, links the new
+binaries, runs 'ggt node check', starts the node in the background and waits
+for it and all its chains to be healthy.
+
+If anything fails after the snapshot, 'ggt node rollback work-dir' restores the
+previous binaries and data (or use --rollback to do that automatically).
+
+  ggt node upgrade MyNode --ava-bin dijetsnode-v1.9.8 --vm subnetevm=subnet-evm-v0.4.9`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			return upgrade(args[0])
+		},
+	}
+	cmd.Flags().String("ava-bin", "", "New dijetsnode binary")
+	cmd.Flags().String("ava-version", "", "New avalanchego version from 'ggt bin', installing it if needed, instead of --ava-bin")
+	cmd.Flags().StringSlice("vm", []string{}, "New VM binaries, as name=vm-bin")
+	cmd.Flags().Duration("timeout", 30*time.Second, "How long to wait for a graceful shutdown")
+	cmd.Flags().Duration("health-timeout", 2*time.Minute, "How long to wait for the upgraded node to be healthy")
+	cmd.Flags().Bool("rollback", false, "Roll back automatically if the upgraded node fails its checks or doesn't become healthy")
+	cmd.Flags().Bool("skip-check", false, "Don't run 'ggt node check' or the rpcchainvm compatibility check")
+	return cmd
+}
+
+func upgrade(workDir string) error {
+	if !utils.DirExists(workDir) {
+		return fmt.Errorf("node directory does not exist: %s", workDir)
+	}
+	files := utils.NewFileLocations(workDir)
+
+	avaBin := viper.GetString("ava-bin")
+	if v := viper.GetString("ava-version"); v != "" {
+		path, err := bincmd.Resolve(binaries.AvalancheGo, v)
+		if err != nil {
+			return err
+		}
+		avaBin = path
+	}
+	if avaBin != "" && !utils.FileExists(avaBin) {
+		return fmt.Errorf("ava-bin file does not exist: %s", avaBin)
+	}
+	vms := map[string]string{}
+	for _, entry := range viper.GetStringSlice("vm") {
+		name, vmBin, found := strings.Cut(entry, "=")
+		if !found {
+			return fmt.Errorf("invalid --vm %q, expected name=vm-bin", entry)
+		}
+		if !utils.FileExists(vmBin) {
+			return fmt.Errorf("vm-bin file does not exist: %s", vmBin)
+		}
+		vms[name] = vmBin
+	}
+	if avaBin == "" && len(vms) == 0 {
+		return errors.New("nothing to upgrade, supply --ava-bin, --ava-version or --vm")
+	}
+
+	// Refuse before touching the node if the new set of binaries can't work together
+	if !viper.GetBool("skip-check") {
+		current, err := vmcompat.Check(workDir)
+		if err != nil {
+			return err
+		}
+		plugins := map[string]string{}
+		for _, p := range current.Plugins {
+			plugins[p.Name] = p.Path
+		}
+		for name, vmBin := range vms {
+			plugins[name] = vmBin
+		}
+		nodeBin := files.AvaBinFile
+		if avaBin != "" {
+			nodeBin = avaBin
+		}
+		if err := checkVMCompat(vmcompat.CheckBinaries(nodeBin, plugins)); err != nil {
+			return err
+		}
+	}
+
+	if err := supervisor.Stop(workDir, viper.GetDuration("timeout")); err != nil && !errors.Is(err, supervisor.ErrNotRunning) {
+		return err
+	}
+
+	name := upgradeSnapshotPrefix + time.Now().UTC().Format("20060102-150405")
+	app.Log.Infof("Saving snapshot %s...", name)
+	snap, err := snapshot.Save(workDir, name, snapshot.Options{Reason: "before upgrade"})
+	if err != nil {
+		return err
+	}
+	app.Log.Infof("Saved %d bytes of data to %s", snap.Size, snapshot.Dir(workDir))
+
+	failed := func(err error) error {
+		if !viper.GetBool("rollback") {
+			return fmt.Errorf("%w\nrun 'ggt node rollback %s' to restore the previous binaries and data", err, workDir)
+		}
+		app.Log.Errorf("Upgrade failed: %s", err)
+		if rerr := rollback(workDir, name, true); rerr != nil {
+			return fmt.Errorf("upgrade failed (%s) and so did rolling back: %w", err, rerr)
+		}
+		return fmt.Errorf("upgrade failed, rolled back to %s: %w", name, err)
+	}
+
+	if avaBin != "" {
+		app.Log.Infof("Linking %s to %s", avaBin, files.AvaBinFile)
+		if err := os.Remove(files.AvaBinFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return failed(err)
+		}
+		if err := utils.LinkFile(avaBin, files.AvaBinFile); err != nil {
+			return failed(err)
+		}
+	}
+	for name, vmBin := range vms {
+		if err := LinkVM(workDir, name, vmBin); err != nil {
+			return failed(err)
+		}
+	}
+
+	if !viper.GetBool("skip-check") {
+		if err := checkWorkDir(workDir); err != nil {
+			return failed(err)
+		}
+	}
+	if err := startAndWaitHealthy(workDir, viper.GetDuration("health-timeout")); err != nil {
+		return failed(err)
+	}
+	app.Log.Infof("Upgraded node in %s is healthy", workDir)
+	return nil
+}
+
+// startAndWaitHealthy starts the node in the background and waits for all its chains to be healthy.
+func startAndWaitHealthy(workDir string, timeout time.Duration) error {
+	state, err := supervisor.StartDetached(workDir, "--skip-check")
+	if err != nil {
+		return withDiagnosis(workDir, err)
+	}
+	app.Log.Infof("Node started in background with pid %d, waiting for it to be healthy...", state.Pid)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if _, err := nodeclient.New(utils.NodeURL(workDir)).WaitHealthy(ctx, time.Second, nodeclient.IgnoredHealthChecks(workDir)...); err != nil {
+		return withDiagnosis(workDir, err)
+	}
+	return nil
+}
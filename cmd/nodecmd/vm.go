@@ -0,0 +1,22 @@
+package nodecmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newVMCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vm",
+		Short: "Add, list and remove the VM plugins of a prepared node",
+		Long: `Each VM is a link in bin/plugins named after its VM ID, plus an alias in
+configs/vms/aliases.json. These commands keep the two in sync.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(newVMAddCmd())
+	cmd.AddCommand(newVMListCmd())
+	cmd.AddCommand(newVMRemoveCmd())
+	return cmd
+}
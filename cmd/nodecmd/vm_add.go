@@ -0,0 +1,48 @@
+package nodecmd
+
+import (
+	"fmt"
+
+	"github.com/lasthyphen/ecctools/pkg/supervisor"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/lasthyphen/ecctools/pkg/vmcompat"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newVMAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add work-dir name vm-bin",
+		Short: "Link vm-bin into the node as the VM name, replacing any existing plugin for it",
+		Long: `The node only loads new VMs when it starts or when admin.loadVMs is called, so
+a running node needs 'ggt node load-vms' (new VMs) or 'ggt node restart'
+(replaced VMs) afterwards.`,
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			workDir, name, vmBin := args[0], args[1], args[2]
+			if !utils.DirExists(workDir) {
+				return fmt.Errorf("node directory does not exist: %s", workDir)
+			}
+			if !utils.FileExists(vmBin) {
+				return fmt.Errorf("vm-bin file does not exist: %s", vmBin)
+			}
+			if !viper.GetBool("skip-check") {
+				report := vmcompat.CheckBinaries(utils.NewFileLocations(workDir).AvaBinFile, map[string]string{name: vmBin})
+				if err := checkVMCompat(report); err != nil {
+					return err
+				}
+			}
+
+			if err := LinkVM(workDir, name, vmBin); err != nil {
+				return err
+			}
+			if state, err := supervisor.LoadState(workDir); err == nil && state.IsRunning() {
+				app.Log.Infof("Node is running, use 'ggt node load-vms' to load a new VM or 'ggt node restart %s' to pick up a replaced one", workDir)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Bool("skip-check", false, "Don't check vm-bin speaks the same rpcchainvm protocol as the node")
+	return cmd
+}
@@ -0,0 +1,138 @@
+package nodecmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/lasthyphen/dijetsnode/ids"
+	"github.com/lasthyphen/ecctools/pkg/nodeclient"
+	"github.com/lasthyphen/ecctools/pkg/supervisor"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/lasthyphen/ecctools/pkg/vmcompat"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tidwall/gjson"
+)
+
+type vmEntry struct {
+	VMID    string   `json:"vmID"`
+	Aliases []string `json:"aliases"`
+	// Binary the plugin links to, empty if there is only an alias
+	Target     string `json:"target"`
+	Version    string `json:"version"`
+	RPCChainVM uint   `json:"rpcchainvm"`
+	// Whether the running node reports the VM in info.getVMs
+	Loaded bool   `json:"loaded"`
+	Error  string `json:"error,omitempty"`
+}
+
+func newVMListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list work-dir",
+		Short: "List the node's VMs with their binary, version and whether the running node has loaded them",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			entries, running, err := listVMs(args[0])
+			if err != nil {
+				return err
+			}
+			if viper.GetBool("json") {
+				b, err := json.MarshalIndent(entries, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(b))
+				return nil
+			}
+
+			fmt.Printf("%-50s %-16s %-24s %-7s %s\n", "VMID", "ALIAS", "VERSION", "LOADED", "TARGET")
+			for _, e := range entries {
+				alias, loaded := "", "-"
+				if len(e.Aliases) > 0 {
+					alias = e.Aliases[0]
+				}
+				if running {
+					loaded = fmt.Sprint(e.Loaded)
+				}
+				target := e.Target
+				if target == "" {
+					target = "(missing plugin)"
+				}
+				fmt.Printf("%-50s %-16s %-24s %-7s %s\n", e.VMID, alias, e.Version, loaded, target)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Bool("json", false, "Output JSON")
+	return cmd
+}
+
+// listVMs returns every VM with a plugin link or an alias, and whether the node is running
+func listVMs(workDir string) ([]vmEntry, bool, error) {
+	if !utils.DirExists(workDir) {
+		return nil, false, fmt.Errorf("node directory does not exist: %s", workDir)
+	}
+	dirs := utils.NewDirectoryLayout(workDir)
+	files := utils.NewFileLocations(workDir)
+
+	byID := map[string]*vmEntry{}
+	entry := func(vmID string) *vmEntry {
+		if _, ok := byID[vmID]; !ok {
+			byID[vmID] = &vmEntry{VMID: vmID, Aliases: []string{}}
+		}
+		return byID[vmID]
+	}
+	if b, err := os.ReadFile(files.VMAliasesFile); err == nil {
+		gjson.ParseBytes(b).ForEach(func(vmID, aliases gjson.Result) bool {
+			e := entry(vmID.String())
+			for _, a := range aliases.Array() {
+				e.Aliases = append(e.Aliases, a.String())
+			}
+			return true
+		})
+	}
+	plugins, err := os.ReadDir(dirs.PluginDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, false, err
+	}
+	for _, p := range plugins {
+		e := entry(p.Name())
+		name := e.VMID
+		if len(e.Aliases) > 0 {
+			name = e.Aliases[0]
+		}
+		bin := vmcompat.Probe(name, filepath.Join(dirs.PluginDir, p.Name()), false)
+		e.Target, e.Version, e.RPCChainVM, e.Error = bin.Path, bin.Version, bin.RPCChainVM, bin.Error
+	}
+
+	state, err := supervisor.LoadState(workDir)
+	if err != nil {
+		return nil, false, err
+	}
+	running := state.IsRunning()
+	if running {
+		loaded, err := nodeclient.New(utils.NodeURL(workDir)).Info.GetVMs(context.Background())
+		if err != nil {
+			app.Log.Warnf("info.getVMs failed: %s", err)
+		}
+		for vmID := range byID {
+			id, err := ids.FromString(vmID)
+			if err != nil {
+				continue
+			}
+			_, byID[vmID].Loaded = loaded[id]
+		}
+	}
+
+	out := []vmEntry{}
+	for _, e := range byID {
+		out = append(out, *e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].VMID < out[j].VMID })
+	return out, running, nil
+}
@@ -0,0 +1,25 @@
+package nodecmd
+
+import (
+	"github.com/lasthyphen/ecctools/pkg/supervisor"
+	"github.com/spf13/cobra"
+)
+
+func newVMRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove work-dir name",
+		Short: "Remove the VM name's plugin link and alias from the node",
+		Long:  `Chains already running the VM keep running until the node is restarted.`,
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := UnlinkVM(args[0], args[1]); err != nil {
+				return err
+			}
+			if state, err := supervisor.LoadState(args[0]); err == nil && state.IsRunning() {
+				app.Log.Infof("Node is running, it keeps %s loaded until 'ggt node restart %s'", args[1], args[0])
+			}
+			return nil
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,42 @@
+package nodecmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/lasthyphen/ecctools/pkg/nodeclient"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newWaitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "wait [work-dir]",
+		Short: "Wait until the node is bootstrapped and healthy, and optionally a chain is serving RPC",
+		Long: `Polls info.isBootstrapped for P, X and C, health.health and, with --chain, the
+chain's bootstrap status and RPC until they are all ready. Fails with whatever
+wasn't ready, including the failing health checks, after --timeout. Use it in
+scripts instead of sleeping:
+
+  ggt node start -d MyNode && ggt node wait MyNode && ggt wallet create-chain MyNode ...`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			workDir := workDirArg(args, 0)
+			uri := utils.ResolveNodeURL(workDir)
+			ctx, cancel := context.WithTimeout(context.Background(), viper.GetDuration("timeout"))
+			defer cancel()
+			start := time.Now()
+			if err := nodeclient.New(uri).WaitReady(ctx, viper.GetString("chain"), viper.GetDuration("interval"), nodeclient.IgnoredHealthChecks(workDir)...); err != nil {
+				return err
+			}
+			app.Log.Infof("Node at %s is ready after %s", uri, time.Since(start).Round(time.Second))
+			return nil
+		},
+	}
+	cmd.Flags().String("chain", "", "Also wait for this chain (name, alias or ID) to be bootstrapped and serving RPC")
+	cmd.Flags().Duration("timeout", 2*time.Minute, "How long to wait")
+	cmd.Flags().Duration("interval", time.Second, "How often to poll")
+	return cmd
+}
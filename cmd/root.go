@@ -5,12 +5,17 @@ import (
 	"os"
 	"strings"
 
+	"github.com/lasthyphen/ecctools/cmd/bincmd"
 	"github.com/lasthyphen/ecctools/cmd/castcmd"
+	"github.com/lasthyphen/ecctools/cmd/genesiscmd"
+	"github.com/lasthyphen/ecctools/cmd/networkcmd"
 	"github.com/lasthyphen/ecctools/cmd/nodecmd"
 	"github.com/lasthyphen/ecctools/cmd/subnetcmd"
 	"github.com/lasthyphen/ecctools/cmd/utilscmd"
+	"github.com/lasthyphen/ecctools/cmd/vmcmd"
 	"github.com/lasthyphen/ecctools/cmd/walletcmd"
 	"github.com/lasthyphen/ecctools/pkg/application"
+	"github.com/lasthyphen/ecctools/pkg/utils"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -61,14 +66,23 @@ To get started, run these commands in an empty directory:
 	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.config/ggt.json)")
 	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "output more verbose logs")
 	rootCmd.PersistentFlags().String("node-url", "http://localhost:9650", "Dijets node URL")
+	rootCmd.PersistentFlags().Duration("rpc-timeout", utils.DefaultRPCOptions.Timeout, "Timeout for each request to the node")
+	rootCmd.PersistentFlags().Int("rpc-retries", utils.DefaultRPCOptions.Retries, "Retries for requests to the node that fail to connect or return a 5xx")
 	_ = viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
 	_ = viper.BindPFlag("node-url", rootCmd.PersistentFlags().Lookup("node-url"))
+	_ = viper.BindPFlag("rpc-timeout", rootCmd.PersistentFlags().Lookup("rpc-timeout"))
+	_ = viper.BindPFlag("rpc-retries", rootCmd.PersistentFlags().Lookup("rpc-retries"))
 
+	rootCmd.AddCommand(bincmd.NewCmd(app))
 	rootCmd.AddCommand(castcmd.NewCmd(app))
+	rootCmd.AddCommand(genesiscmd.NewCmd(app))
+	rootCmd.AddCommand(networkcmd.NewCmd(app))
 	rootCmd.AddCommand(nodecmd.NewCmd(app))
 	rootCmd.AddCommand(subnetcmd.NewCmd(app))
 	rootCmd.AddCommand(utilscmd.NewCmd(app))
+	rootCmd.AddCommand(vmcmd.NewCmd(app))
 	rootCmd.AddCommand(walletcmd.NewCmd(app))
+	rootCmd.AddCommand(newUpCmd())
 	rootCmd.AddCommand(versionCmd)
 	return rootCmd
 }
@@ -101,6 +115,10 @@ func initApp(_ *cobra.Command, _ []string) error {
 	if viper.GetBool("verbose") {
 		app.Verbose()
 	}
+	opts := utils.DefaultRPCOptions
+	opts.Timeout = viper.GetDuration("rpc-timeout")
+	opts.Retries = viper.GetInt("rpc-retries")
+	utils.SetRPCOptions(opts)
 	return nil
 }
 
@@ -0,0 +1,386 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lasthyphen/dijetsnode/ids"
+	"github.com/lasthyphen/dijetsnode/utils/crypto/secp256k1"
+	"github.com/lasthyphen/ecctools/cmd/bincmd"
+	"github.com/lasthyphen/ecctools/cmd/nodecmd"
+	"github.com/lasthyphen/ecctools/cmd/utilscmd"
+	"github.com/lasthyphen/ecctools/cmd/walletcmd"
+	"github.com/lasthyphen/ecctools/pkg/binaries"
+	"github.com/lasthyphen/ecctools/pkg/configs"
+	"github.com/lasthyphen/ecctools/pkg/manifest"
+	"github.com/lasthyphen/ecctools/pkg/supervisor"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+func newUpCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "up",
+		Short: "Create or update the project in the current dir to match ggt.yaml",
+		Long: `Reads the project manifest (ggt.yaml) and creates whatever is missing:
+binaries, node dirs, subnets and chains. Running it again is safe, anything that
+already exists is left alone, except that existing node dirs have their node
+binary and VM plugins relinked where they don't match ggt.yaml. The IDs of created subnets and chains are recorded
+in ggt.lock.json, commit it along with ggt.yaml so teammates get the same setup.
+
+Nodes that need subnets or chains created are started in the background
+(see 'ggt node status|stop').`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+
+			m, err := manifest.Load(viper.GetString("manifest"))
+			if err != nil {
+				return err
+			}
+			lockPath := viper.GetString("lock")
+			lock, err := manifest.LoadLock(lockPath)
+			if err != nil {
+				return err
+			}
+			key, err := walletcmd.DecodePrivateKey(viper.GetString("pk"))
+			if err != nil {
+				return err
+			}
+
+			if err := up(m, lock, lockPath, key); err != nil {
+				return err
+			}
+			app.Log.Infof("Project is up to date with %s", viper.GetString("manifest"))
+			return nil
+		},
+	}
+	cmd.Flags().String("manifest", manifest.ManifestFilename, "Project manifest")
+	cmd.Flags().String("lock", manifest.LockFilename, "Lock file recording created subnet and chain IDs")
+	cmd.Flags().String("pk", walletcmd.DefaultPrivateKey, "Private key used to create subnets and chains")
+	cmd.Flags().Duration("timeout", 2*time.Minute, "How long to wait for nodes to bootstrap")
+	return cmd
+}
+
+func up(m *manifest.Manifest, lock *manifest.Lock, lockPath string, key *secp256k1.PrivateKey) error {
+	utilscmd.WriteDefaultFiles()
+
+	if err := upBinaries(m); err != nil {
+		return err
+	}
+	if err := upNodes(m); err != nil {
+		return err
+	}
+
+	// Subnets and chains can only be created on a running node
+	for _, name := range nodesWithChains(m) {
+		if err := ensureRunning(name, viper.GetDuration("timeout")); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range m.Subnets {
+		if err := upSubnet(s, lock, key); err != nil {
+			return err
+		}
+		if err := lock.Save(lockPath); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range m.Chains {
+		if err := upChain(c, lock, key); err != nil {
+			return err
+		}
+		if err := lock.Save(lockPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func upBinaries(m *manifest.Manifest) error {
+	if v := m.Versions["avalanchego"]; v != "" {
+		if _, err := bincmd.ResolveLocal(".", binaries.AvalancheGo, v); err != nil {
+			return err
+		}
+	}
+	if v := m.Versions["subnetevm"]; v != "" {
+		if _, err := bincmd.ResolveLocal(".", binaries.SubnetEVM, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func avaBinName(version string) string {
+	return fmt.Sprintf("dijetsnode-%s", version)
+}
+
+func upNodes(m *manifest.Manifest) error {
+	for _, n := range m.Nodes {
+		avaBin := n.AvaBin
+		if avaBin == "" {
+			avaBin = avaBinName(m.Versions["avalanchego"])
+		}
+
+		if utils.DirExists(n.Name) {
+			if err := syncNode(n, avaBin); err != nil {
+				return err
+			}
+			continue
+		}
+
+		vmNames := n.VMNames()
+		vmName, vmBin := "", ""
+		if len(vmNames) > 0 {
+			vmName, vmBin = vmNames[0], n.VMs[vmNames[0]]
+		}
+		if err := nodecmd.PrepareWorkDir(n.Name, avaBin, vmBin, vmName); err != nil {
+			return err
+		}
+		if len(vmNames) > 1 {
+			for _, name := range vmNames[1:] {
+				if err := nodecmd.LinkVM(n.Name, name, n.VMs[name]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// syncNode relinks the node binary and VM plugins of an existing node dir that
+// are missing or point at a different binary than the manifest says
+func syncNode(n manifest.Node, avaBin string) error {
+	files := utils.NewFileLocations(n.Name)
+	changed := false
+
+	if !linksTo(files.AvaBinFile, avaBin) {
+		app.Log.Infof("Linking %s to %s", avaBin, files.AvaBinFile)
+		if err := os.Remove(files.AvaBinFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if err := utils.LinkFile(avaBin, files.AvaBinFile); err != nil {
+			return fmt.Errorf("failed linking file: %w", err)
+		}
+		changed = true
+	}
+
+	for _, name := range n.VMNames() {
+		vmID, err := utils.VMID(name)
+		if err != nil {
+			return err
+		}
+		fn := filepath.Join(utils.NewDirectoryLayout(n.Name).PluginDir, vmID.String())
+		if linksTo(fn, n.VMs[name]) {
+			continue
+		}
+		if err := nodecmd.LinkVM(n.Name, name, n.VMs[name]); err != nil {
+			return err
+		}
+		changed = true
+	}
+
+	if !changed {
+		app.Log.Infof("Node %s exists and matches the manifest, skipping", n.Name)
+		return nil
+	}
+	if state, err := supervisor.LoadState(n.Name); err == nil && state.IsRunning() {
+		app.Log.Warnf("Node %s is running, restart it to use the new binaries (see 'ggt node restart')", n.Name)
+	}
+	return nil
+}
+
+// linksTo reports whether link exists and resolves to the same file as target
+func linksTo(link string, target string) bool {
+	got, err := filepath.EvalSymlinks(link)
+	if err != nil {
+		return false
+	}
+	want, err := filepath.EvalSymlinks(target)
+	if err != nil {
+		return false
+	}
+	return got == want
+}
+
+func nodesWithChains(m *manifest.Manifest) []string {
+	seen := map[string]bool{}
+	out := []string{}
+	for _, s := range m.Subnets {
+		if !seen[s.Node] {
+			seen[s.Node] = true
+			out = append(out, s.Node)
+		}
+	}
+	for _, c := range m.Chains {
+		if !seen[c.Node] {
+			seen[c.Node] = true
+			out = append(out, c.Node)
+		}
+	}
+	return out
+}
+
+func ensureRunning(workDir string, timeout time.Duration) error {
+	state, err := supervisor.LoadState(workDir)
+	if err != nil {
+		return err
+	}
+	if !state.IsRunning() {
+		app.Log.Infof("Starting node %s in the background...", workDir)
+		if _, err := supervisor.StartDetached(workDir); err != nil {
+			return err
+		}
+	}
+
+	uri := utils.ResolveNodeURL(workDir)
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		result, err := utils.FetchRPCGJSON(fmt.Sprintf("%s/ext/info", uri), "info.isBootstrapped", `{"chain":"P"}`)
+		if err == nil && result.Get("result.isBootstrapped").Bool() {
+			return nil
+		}
+		time.Sleep(time.Second)
+	}
+	return fmt.Errorf("timed out waiting for node %s to bootstrap", workDir)
+}
+
+func upSubnet(s manifest.Subnet, lock *manifest.Lock, key *secp256k1.PrivateKey) error {
+	uri := utils.ResolveNodeURL(s.Node)
+
+	if locked, ok := lock.Subnets[s.Name]; ok {
+		getSubnets, err := utils.FetchRPCGJSON(fmt.Sprintf("%s/ext/bc/P", uri), "platform.getSubnets", "")
+		if err != nil {
+			return err
+		}
+		for _, obj := range getSubnets.Get("result.subnets").Array() {
+			if obj.Get("id").String() == locked.ID {
+				app.Log.Infof("Subnet %s exists with ID %s, skipping", s.Name, locked.ID)
+				return nil
+			}
+		}
+		app.Log.Warnf("Subnet %s (%s) in %s not found on node %s, creating it again", s.Name, locked.ID, manifest.LockFilename, s.Node)
+	}
+
+	subnetID, err := walletcmd.CreateSubnet(uri, key)
+	if err != nil {
+		return err
+	}
+	if locked, ok := lock.Subnets[s.Name]; ok && locked.ID != subnetID.String() {
+		app.Log.Warnf("Subnet %s was created with ID %s, %s had %s", s.Name, subnetID, manifest.LockFilename, locked.ID)
+	}
+	app.Log.Infof("Created subnet %s with ID %s", s.Name, subnetID)
+	lock.Subnets[s.Name] = manifest.LockedSubnet{ID: subnetID.String(), Node: s.Node}
+	return nil
+}
+
+func upChain(c manifest.Chain, lock *manifest.Lock, key *secp256k1.PrivateKey) error {
+	uri := utils.ResolveNodeURL(c.Node)
+
+	getBlockchains, err := utils.FetchRPCGJSON(fmt.Sprintf("%s/ext/bc/P", uri), "platform.getBlockchains", "")
+	if err != nil {
+		return err
+	}
+	for _, obj := range getBlockchains.Get("result.blockchains").Array() {
+		if obj.Get("name").String() == c.Name {
+			app.Log.Infof("Chain %s exists with ID %s, skipping", c.Name, obj.Get("id").String())
+			lock.Chains[c.Name] = manifest.LockedChain{
+				ID:       obj.Get("id").String(),
+				SubnetID: obj.Get("subnetID").String(),
+				VMID:     obj.Get("vmID").String(),
+				Node:     c.Node,
+			}
+			return nil
+		}
+	}
+
+	vmID, err := utils.VMID(c.VM)
+	if err != nil {
+		return fmt.Errorf("chain %s: %w", c.Name, err)
+	}
+	subnetID, err := ids.FromString(lock.Subnets[c.Subnet].ID)
+	if err != nil {
+		return fmt.Errorf("subnet %s for chain %s has not been created: %w", c.Subnet, c.Name, err)
+	}
+
+	genesisBytes, err := chainGenesis(c)
+	if err != nil {
+		return err
+	}
+
+	chainID, err := walletcmd.CreateChain(c.Node, key, subnetID, c.Name, c.VM, genesisBytes, c.Config)
+	if err != nil {
+		return err
+	}
+	if locked, ok := lock.Chains[c.Name]; ok && locked.ID != chainID.String() {
+		app.Log.Warnf("Chain %s was created with ID %s, %s had %s", c.Name, chainID, manifest.LockFilename, locked.ID)
+	}
+	app.Log.Infof("Created chain %s with ID %s", c.Name, chainID)
+	app.Log.Infof("RPC: %s/ext/bc/%s/rpc", uri, chainID)
+	lock.Chains[c.Name] = manifest.LockedChain{
+		ID:       chainID.String(),
+		SubnetID: subnetID.String(),
+		VMID:     vmID.String(),
+		Node:     c.Node,
+	}
+	return nil
+}
+
+// chainGenesis reads the chain's genesis file and funds the accounts in its alloc
+func chainGenesis(c manifest.Chain) ([]byte, error) {
+	genesisBytes, err := os.ReadFile(c.Genesis)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.Alloc) == 0 {
+		return genesisBytes, nil
+	}
+
+	accounts, err := utils.LoadJSON(configs.AccountsFilename)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sorted, so the genesis and the chain ID it leads to are the same every run
+	names := make([]string, 0, len(c.Alloc))
+	for name := range c.Alloc {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	genesis := string(genesisBytes)
+	for _, name := range names {
+		amount := c.Alloc[name]
+		addr := accounts.Get(name).Get("addr").String()
+		if addr == "" {
+			addr = name
+		}
+		if !strings.HasPrefix(addr, "0x") {
+			return nil, fmt.Errorf("chain %s alloc: unknown account %q", c.Name, name)
+		}
+		wei, ok := new(big.Int).SetString(utils.ResolveAmounts([]string{amount})[0], 10)
+		if !ok {
+			return nil, fmt.Errorf("chain %s alloc: invalid amount %q for %s", c.Name, amount, name)
+		}
+		key := fmt.Sprintf("alloc.%s.balance", strings.ToLower(strings.TrimPrefix(addr, "0x")))
+		genesis, err = sjson.Set(genesis, key, fmt.Sprintf("0x%x", wei))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !gjson.Valid(genesis) {
+		return nil, fmt.Errorf("invalid genesis for chain %s", c.Name)
+	}
+	return []byte(genesis), nil
+}
@@ -1,7 +1,10 @@
 package utilscmd
 
 import (
+	"github.com/lasthyphen/ecctools/cmd/bincmd"
+	"github.com/lasthyphen/ecctools/pkg/binaries"
 	"github.com/lasthyphen/ecctools/pkg/configs"
+	"github.com/lasthyphen/ecctools/pkg/genesis"
 	"github.com/lasthyphen/ecctools/pkg/utils"
 	"github.com/spf13/cobra"
 )
@@ -12,49 +15,23 @@ func newInitCmd() *cobra.Command {
 		Use:   "init [avago-version] [subnet-evm-version]",
 		Short: "Create default files in the current dir",
 		Long: `Create default config files in the current dir, and
-also attempt to download avalanchego and subnet-evm binaries from
-GitHub.
+also install avalanchego and subnet-evm into the 'ggt bin' cache and
+link them into the current dir.
 
 Example:  ggt init v1.9.7 v0.4.8
 `,
 		Run: func(cmd *cobra.Command, args []string) {
-			files := make(map[string]string)
-			files["README.md"] = configs.Readme
-			files["subnetevm-genesis.json"] = configs.SubnetEVMGenesis
-			files["subnetevm-config.json"] = configs.SubnetEVMConfig
-			files[configs.AccountsFilename] = configs.Accounts
-			files[configs.CChainConfigFilename] = configs.CChainConfig
-			files[configs.ContractsFilename] = configs.Contracts
-			files[configs.NodeConfigFilename] = configs.NodeConfig
-			files[configs.XChainConfigFilename] = configs.XChainConfig
-			files[configs.AvaGenesisFilename] = configs.AvaGenesis
-
-			for fn, content := range files {
-				if utils.FileExists(fn) {
-					app.Log.Infof("File exists, skipping %s", fn)
-				} else {
-					app.Log.Infof("Creating %s", fn)
-					_ = utils.WriteFileBytes(fn, []byte(content))
-				}
-			}
+			WriteDefaultFiles()
 
 			if len(args) > 0 && args[0] != "" {
-				app.Log.Infof("Downloading avalanchego...")
-				url, destFile, err := utils.DownloadAvalanchego(".", args[0])
-				if err != nil {
-					app.Log.Warnf("Error downloading %s: %s", url, err)
-				} else {
-					app.Log.Infof("Downloaded %s to %s", url, destFile)
+				if _, err := bincmd.ResolveLocal(".", binaries.AvalancheGo, args[0]); err != nil {
+					app.Log.Warnf("Error installing avalanchego %s: %s", args[0], err)
 				}
 			}
 
 			if len(args) > 1 && args[1] != "" {
-				app.Log.Infof("Downloading subnetevm...")
-				url, destFile, err := utils.DownloadSubnetevm(".", args[1])
-				if err != nil {
-					app.Log.Warnf("Error downloading %s: %s", url, err)
-				} else {
-					app.Log.Infof("Downloaded %s to %s", url, destFile)
+				if _, err := bincmd.ResolveLocal(".", binaries.SubnetEVM, args[1]); err != nil {
+					app.Log.Warnf("Error installing subnetevm %s: %s", args[1], err)
 				}
 			}
 
@@ -63,3 +40,28 @@ Example:  ggt init v1.9.7 v0.4.8
 
 	return cmd
 }
+
+// WriteDefaultFiles creates any of the default config files that don't exist in the current dir
+func WriteDefaultFiles() {
+	files := make(map[string]string)
+	files["README.md"] = configs.Readme
+	files["subnetevm-genesis.json"] = configs.SubnetEVMGenesis
+	files["subnetevm-config.json"] = configs.SubnetEVMConfig
+	files[configs.AccountsFilename] = configs.Accounts
+	files[configs.CChainConfigFilename] = configs.CChainConfig
+	files[configs.ContractsFilename] = configs.Contracts
+	files[configs.NodeConfigFilename] = configs.NodeConfig
+	files[configs.XChainConfigFilename] = configs.XChainConfig
+	avaGenesis, err := genesis.RetimePrimary(configs.AvaGenesis, genesis.DefaultStartTime())
+	cobra.CheckErr(err)
+	files[configs.AvaGenesisFilename] = string(avaGenesis)
+
+	for fn, content := range files {
+		if utils.FileExists(fn) {
+			app.Log.Infof("File exists, skipping %s", fn)
+		} else {
+			app.Log.Infof("Creating %s", fn)
+			_ = utils.WriteFileBytes(fn, []byte(content))
+		}
+	}
+}
@@ -3,7 +3,7 @@ package utilscmd
 import (
 	"fmt"
 
-	"github.com/lasthyphen/dijetsnode/ids"
+	"github.com/lasthyphen/ecctools/pkg/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -35,9 +35,7 @@ func newVMIDCmd() *cobra.Command {
 			// if err != nil {
 			// 	return err
 			// }
-			paddedBytes := [32]byte{}
-			copy(paddedBytes[:], []byte(args[0]))
-			id, err := ids.ToID(paddedBytes[:])
+			id, err := utils.VMID(args[0])
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,215 @@
+package vmcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/lasthyphen/ecctools/cmd/nodecmd"
+	"github.com/lasthyphen/ecctools/pkg/nodeclient"
+	"github.com/lasthyphen/ecctools/pkg/supervisor"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/lasthyphen/ecctools/pkg/vmbuild"
+	"github.com/radovskyb/watcher"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	reloadAuto    = "auto"
+	reloadRestart = "restart"
+	reloadLoadVMs = "load-vms"
+	reloadNone    = "none"
+)
+
+func newBuildCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "build work-dir",
+		Short: "Compile a VM plugin from source and link it into a node",
+		Long: `Runs go build in --src with the git commit in -ldflags, and links the binary
+into the node's plugin dir under the VM ID for --name.
+
+If the node is running it is then reloaded (--reload): 'auto' calls
+admin.loadVMs the first time a VM is linked and gracefully restarts the node
+when an existing plugin was replaced, since the node can't reload a VM it has
+already loaded.
+
+With --watch it keeps running, and rebuilds and reloads whenever .go files,
+go.mod or go.sum in --src change. A failed build leaves the previous plugin in
+place.
+
+  ggt vm build MyNode --src ~/subnet-evm --name subnetevm --watch`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			workDir := args[0]
+			if !utils.DirExists(workDir) {
+				return fmt.Errorf("node directory does not exist: %s", workDir)
+			}
+			switch viper.GetString("reload") {
+			case reloadAuto, reloadRestart, reloadLoadVMs, reloadNone:
+			default:
+				return fmt.Errorf("invalid --reload %q, expected %s, %s, %s or %s", viper.GetString("reload"), reloadAuto, reloadRestart, reloadLoadVMs, reloadNone)
+			}
+
+			name := viper.GetString("name")
+			opts := vmbuild.Options{
+				Src:        viper.GetString("src"),
+				Pkg:        viper.GetString("pkg"),
+				Out:        viper.GetString("out"),
+				VersionVar: viper.GetString("version-var"),
+				LDFlags:    viper.GetString("ldflags"),
+			}
+			if opts.Out == "" {
+				opts.Out = filepath.Join(utils.NewDirectoryLayout(workDir).BinDir, "builds", name)
+			}
+
+			if err := buildAndLink(workDir, name, opts); err != nil {
+				return err
+			}
+			if !viper.GetBool("watch") {
+				return nil
+			}
+			return watch(workDir, name, opts, viper.GetDuration("debounce"))
+		},
+	}
+	cmd.Flags().String("src", ".", "Root of the VM's Go module")
+	cmd.Flags().String("name", "subnetevm", "VM name, the plugin is linked under its VM ID")
+	cmd.Flags().String("pkg", "", "Main package to build, relative to --src (default ./plugin if it exists, otherwise .)")
+	cmd.Flags().String("out", "", "Where to write the binary (default <work-dir>/bin/builds/<name>)")
+	cmd.Flags().String("version-var", "", "Variable to set to the git commit (default <module>/plugin/evm.GitCommit for subnet-evm, otherwise main.GitCommit)")
+	cmd.Flags().String("ldflags", "", "Extra -ldflags for go build")
+	cmd.Flags().String("reload", reloadAuto, "How to make a running node pick up the new plugin: auto, restart, load-vms or none")
+	cmd.Flags().Bool("watch", false, "Rebuild and reload when Go sources in --src change")
+	cmd.Flags().Duration("debounce", time.Second, "With --watch, wait for changes to stop for this long before rebuilding")
+	cmd.Flags().Duration("timeout", 30*time.Second, "How long to wait for the node to shut down gracefully when restarting")
+	return cmd
+}
+
+// buildAndLink builds the plugin, links it into the node and reloads the node if it is running
+func buildAndLink(workDir string, name string, opts vmbuild.Options) error {
+	vmID, err := utils.VMID(name)
+	if err != nil {
+		return err
+	}
+	pluginFile := filepath.Join(utils.NewDirectoryLayout(workDir).PluginDir, vmID.String())
+	target, _ := filepath.EvalSymlinks(pluginFile)
+	alreadyLinked := target != ""
+
+	app.Log.Infof("Building %s from %s...", name, opts.Src)
+	r, err := vmbuild.Build(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+	app.Log.Infof("Built %s (commit %s) in %s", r.Out, r.Commit, r.Duration.Round(time.Millisecond))
+
+	if target != r.Out {
+		if err := nodecmd.LinkVM(workDir, name, r.Out); err != nil {
+			return err
+		}
+	}
+	return reload(workDir, alreadyLinked)
+}
+
+func reload(workDir string, replaced bool) error {
+	state, err := supervisor.LoadState(workDir)
+	if err != nil {
+		return err
+	}
+	mode := viper.GetString("reload")
+	if !state.IsRunning() || mode == reloadNone {
+		return nil
+	}
+	if mode == reloadAuto {
+		mode = reloadLoadVMs
+		if replaced {
+			mode = reloadRestart
+		}
+	}
+
+	if mode == reloadLoadVMs {
+		added, failed, err := nodeclient.New(utils.NodeURL(workDir)).Admin.LoadVMs(context.Background())
+		if err != nil {
+			return fmt.Errorf("admin.loadVMs failed: %w", err)
+		}
+		for vmID, aliases := range added {
+			app.Log.Infof("Loaded VM %s %v", vmID, aliases)
+		}
+		for vmID, msg := range failed {
+			app.Log.Errorf("Failed to load VM %s: %s", vmID, msg)
+		}
+		return nil
+	}
+
+	app.Log.Infof("Restarting node in %s...", workDir)
+	state, err = supervisor.Restart(workDir, viper.GetDuration("timeout"))
+	if err != nil {
+		return err
+	}
+	app.Log.Infof("Node in %s running with pid %d", workDir, state.Pid)
+	return nil
+}
+
+// watch rebuilds once changes to Go sources have settled for debounce, until interrupted
+func watch(workDir string, name string, opts vmbuild.Options, debounce time.Duration) error {
+	root, err := filepath.Abs(opts.Src)
+	if err != nil {
+		return err
+	}
+	w := watcher.New()
+	w.IgnoreHiddenFiles(true)
+	w.FilterOps(watcher.Create, watcher.Write, watcher.Remove, watcher.Rename, watcher.Move)
+	// Hooks run before the watcher's own hidden dir check, so prune dirs here
+	// or .git is walked on every poll
+	w.AddFilterHook(func(info os.FileInfo, fullPath string) error {
+		if info.IsDir() {
+			if fullPath != root && vmbuild.IsIgnoredDir(fullPath) {
+				return filepath.SkipDir
+			}
+			return watcher.ErrSkip
+		}
+		if !vmbuild.IsSource(fullPath) {
+			return watcher.ErrSkip
+		}
+		return nil
+	})
+	if err := w.AddRecursive(opts.Src); err != nil {
+		return err
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- w.Start(250 * time.Millisecond) }()
+	defer w.Close()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sig)
+
+	app.Log.Infof("Watching %s for changes to Go sources (Ctl-C to quit)", opts.Src)
+	var timer <-chan time.Time
+	changed := map[string]bool{}
+	for {
+		select {
+		case event := <-w.Event:
+			changed[event.Path] = true
+			timer = time.After(debounce)
+		case <-timer:
+			timer = nil
+			app.Log.Infof("%d files changed", len(changed))
+			changed = map[string]bool{}
+			if err := buildAndLink(workDir, name, opts); err != nil {
+				app.Log.Errorf("%s, keeping the previous plugin", err)
+			}
+		case err := <-w.Error:
+			app.Log.Warn(err)
+		case err := <-errCh:
+			return err
+		case <-sig:
+			return nil
+		}
+	}
+}
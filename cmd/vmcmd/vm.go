@@ -0,0 +1,24 @@
+package vmcmd
+
+import (
+	"github.com/lasthyphen/ecctools/pkg/application"
+	"github.com/spf13/cobra"
+)
+
+var app *application.GoGoTools
+
+func NewCmd(injectedApp *application.GoGoTools) *cobra.Command {
+	app = injectedApp
+
+	cmd := &cobra.Command{
+		Use:   "vm",
+		Short: "Build VM plugins for a node",
+		Long:  ``,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(newBuildCmd())
+	return cmd
+}
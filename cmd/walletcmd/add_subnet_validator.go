@@ -0,0 +1,217 @@
+package walletcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lasthyphen/dijetsnode/ids"
+	"github.com/lasthyphen/dijetsnode/utils/crypto/secp256k1"
+	"github.com/lasthyphen/dijetsnode/vms/platformvm/validator"
+	"github.com/lasthyphen/dijetsnode/vms/secp256k1fx"
+	"github.com/lasthyphen/ecctools/pkg/supervisor"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+func newAddSubnetValidatorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add-subnet-validator work-dir subnetID",
+		Short: "Issue a AddSubnetValidator tx, make the node track the subnet and restart it",
+		Long: `Adds the node in work-dir as a validator of subnetID, adds the subnet to
+track-subnets in the node's node-config.json and restarts the node so it starts
+syncing the subnet's chains.
+
+The node must already validate the primary network (see 'ggt network create'),
+and the subnet validation period has to fit inside that. --start and --end take
+a time (2006-01-02T15:04:05Z07:00) or a duration from now (1h). --end defaults
+to the end of the node's primary network validation.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			workDir := args[0]
+			if exists := utils.DirExists(workDir); !exists {
+				return fmt.Errorf("node directory does not exist: %s", workDir)
+			}
+			subnetID, err := ids.FromString(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid subnetID %s: %w", args[1], err)
+			}
+			nodeID, err := validatorNodeID(workDir, viper.GetString("node-id"))
+			if err != nil {
+				return err
+			}
+			key, err := DecodePrivateKey(viper.GetString("pk"))
+			cobra.CheckErr(err)
+
+			uri := utils.ResolveNodeURL(workDir)
+			start, err := parseTime(viper.GetString("start"), time.Now().Add(30*time.Second))
+			if err != nil {
+				return err
+			}
+			var end time.Time
+			if viper.GetString("end") == "" {
+				end, err = primaryValidatorEnd(uri, nodeID)
+			} else {
+				end, err = parseTime(viper.GetString("end"), time.Time{})
+			}
+			if err != nil {
+				return err
+			}
+
+			vdr := &validator.SubnetValidator{
+				Validator: validator.Validator{
+					NodeID: nodeID,
+					Start:  uint64(start.Unix()),
+					End:    uint64(end.Unix()),
+					Wght:   viper.GetUint64("weight"),
+				},
+				Subnet: subnetID,
+			}
+			exportFile := viper.GetString("export")
+			txID, err := addSubnetValidator(uri, key, vdr, exportFile)
+			cobra.CheckErr(err)
+			if exportFile != "" {
+				app.Log.Infof("Collect the remaining signatures with 'ggt wallet sign %s %s --pk ...'", workDir, exportFile)
+				app.Log.Infof("then run 'ggt wallet submit %s %s'", workDir, exportFile)
+				return nil
+			}
+			app.Log.Infof("%s validates subnet %s from %s until %s", nodeID, subnetID, start.Format(time.RFC3339), end.Format(time.RFC3339))
+
+			changed, err := TrackSubnet(workDir, subnetID)
+			if err != nil {
+				return err
+			}
+			if changed && !viper.GetBool("no-restart") {
+				if err := restartIfRunning(workDir); err != nil {
+					return err
+				}
+			}
+			fmt.Println(txID)
+			return nil
+		},
+	}
+	cmd.Flags().String("node-id", "", "NodeID of the validator (default is the NodeID of the node in work-dir)")
+	cmd.Flags().Uint64("weight", 20, "Sampling weight of the validator")
+	cmd.Flags().String("start", "", "When the node starts validating the subnet (default 30s from now)")
+	cmd.Flags().String("end", "", "When the node stops validating the subnet (default the end of its primary network validation)")
+	cmd.Flags().Bool("no-restart", false, "Update node-config.json but don't restart the node")
+	cmd.Flags().String("export", "", "Write the partially signed tx to this file instead of issuing it (for multisig subnets)")
+	return cmd
+}
+
+func addSubnetValidator(uri string, key *secp256k1.PrivateKey, vdr *validator.SubnetValidator, exportFile string) (ids.ID, error) {
+	kc := secp256k1fx.NewKeychain(key)
+	ctx := context.Background()
+
+	wallet, err := newSubnetWallet(ctx, uri, kc, vdr.Subnet)
+	if err != nil {
+		return ids.Empty, err
+	}
+	options, err := subnetAuthOptions(ctx, uri, kc, vdr.Subnet)
+	if err != nil {
+		return ids.Empty, err
+	}
+
+	utx, err := wallet.P().Builder().NewAddSubnetValidatorTx(vdr, options...)
+	if err != nil {
+		return ids.Empty, fmt.Errorf("failed to build AddSubnetValidatorTx: %w", err)
+	}
+
+	txID, err := signAndIssue(ctx, wallet, utx, exportFile)
+	if err != nil {
+		return ids.Empty, fmt.Errorf("failed to issue AddSubnetValidatorTx: %w", err)
+	}
+	return txID, nil
+}
+
+// primaryValidatorEnd is when nodeID stops validating the primary network
+func primaryValidatorEnd(uri string, nodeID ids.NodeID) (time.Time, error) {
+	params := fmt.Sprintf(`{"nodeIDs":["%s"]}`, nodeID)
+	result, err := utils.FetchRPCGJSON(fmt.Sprintf("%s/ext/bc/P", uri), "platform.getCurrentValidators", params)
+	if err != nil {
+		return time.Time{}, err
+	}
+	endTime := result.Get("result.validators.0.endTime").Int()
+	if endTime == 0 {
+		return time.Time{}, fmt.Errorf("%s is not a primary network validator", nodeID)
+	}
+	return time.Unix(endTime, 0), nil
+}
+
+// parseTime accepts RFC3339 or a duration from now, returning def for ""
+func parseTime(s string, def time.Time) (time.Time, error) {
+	if s == "" {
+		return def, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected a duration or %s", s, time.RFC3339)
+	}
+	return t, nil
+}
+
+// TrackSubnet adds subnetID to track-subnets in the node's node-config.json
+// (migrating the deprecated whitelisted-subnets) and returns whether it changed.
+func TrackSubnet(workDir string, subnetID ids.ID) (bool, error) {
+	configFile := utils.NewFileLocations(workDir).ConfigFile
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		return false, err
+	}
+	cfg := gjson.ParseBytes(content)
+
+	current := cfg.Get("track-subnets")
+	if !current.Exists() {
+		current = cfg.Get("whitelisted-subnets")
+	}
+	subnets := []string{}
+	for _, s := range strings.Split(current.String(), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			subnets = append(subnets, s)
+		}
+	}
+	for _, s := range subnets {
+		if s == subnetID.String() {
+			return false, nil
+		}
+	}
+	subnets = append(subnets, subnetID.String())
+
+	out, err := sjson.Set(string(content), "track-subnets", strings.Join(subnets, ","))
+	if err != nil {
+		return false, err
+	}
+	out, err = sjson.Delete(out, "whitelisted-subnets")
+	if err != nil {
+		return false, err
+	}
+	app.Log.Infof("Adding %s to track-subnets in %s", subnetID, configFile)
+	return true, os.WriteFile(configFile, []byte(out), 0644)
+}
+
+func restartIfRunning(workDir string) error {
+	state, err := supervisor.LoadState(workDir)
+	if err != nil {
+		return err
+	}
+	if !state.IsRunning() {
+		app.Log.Infof("Node in %s is not running, it will track the subnet when started", workDir)
+		return nil
+	}
+	app.Log.Infof("Restarting node in %s to track the subnet", workDir)
+	state, err = supervisor.Restart(workDir, 30*time.Second)
+	if err != nil {
+		return err
+	}
+	app.Log.Infof("Node in %s running with pid %d", workDir, state.Pid)
+	return nil
+}
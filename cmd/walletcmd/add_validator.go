@@ -8,7 +8,7 @@ import (
 	"github.com/lasthyphen/dijetsnode/ids"
 	"github.com/lasthyphen/dijetsnode/utils/crypto/secp256k1"
 	"github.com/lasthyphen/dijetsnode/utils/units"
-	"github.com/lasthyphen/dijetsnode/vms/platformvm/txs"
+	"github.com/lasthyphen/dijetsnode/vms/platformvm/validator"
 	"github.com/lasthyphen/dijetsnode/vms/secp256k1fx"
 	"github.com/lasthyphen/dijetsnode/wallet/subnet/primary"
 	"github.com/lasthyphen/ecctools/pkg/utils"
@@ -16,31 +16,53 @@ import (
 	"github.com/spf13/viper"
 )
 
-// TODO This doesnt seem to work on a --staking-disabled node, so maybe not worth including?
-
 func newAddValidatorCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "add-validator work-dir",
 		Short: "Issue a AddValidator tx and return the txID",
-		Long:  ``,
-		Args:  cobra.ExactArgs(1),
+		Long: `Adds the node in work-dir as a primary network validator. The node needs a
+persistent staking cert (see 'ggt network create'), or pass --node-id to add some
+other node. Validators can't be added to single nodes, which run with staking disabled.`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
 			if exists := utils.DirExists(args[0]); !exists {
 				return fmt.Errorf("node directory does not exist: %s", args[0])
 			}
-			key, err := decodePrivateKey(viper.GetString("pk"))
+			nodeID, err := validatorNodeID(args[0], viper.GetString("node-id"))
+			if err != nil {
+				return err
+			}
+			key, err := DecodePrivateKey(viper.GetString("pk"))
 			cobra.CheckErr(err)
-			txID, err := addValidator(key)
+			txID, err := addValidator(utils.ResolveNodeURL(args[0]), key, nodeID, viper.GetDuration("duration"))
 			cobra.CheckErr(err)
 			fmt.Println(txID)
 			return nil
 		},
 	}
+	cmd.Flags().String("node-id", "", "NodeID of the validator (default is the NodeID of the node in work-dir)")
+	cmd.Flags().Duration("duration", 24*time.Hour, "How long the node validates for")
 	return cmd
 }
 
-func addValidator(key *secp256k1.PrivateKey) (ids.ID, error) {
-	uri := viper.GetString("node-url")
+// validatorNodeID returns nodeID if set, otherwise the NodeID from workDir's staking cert
+func validatorNodeID(workDir string, nodeID string) (ids.NodeID, error) {
+	if nodeID != "" {
+		id, err := ids.NodeIDFromString(nodeID)
+		if err != nil {
+			return ids.EmptyNodeID, fmt.Errorf("error decoding nodeID %s: %w", nodeID, err)
+		}
+		return id, nil
+	}
+	id, err := utils.NodeID(workDir)
+	if err != nil {
+		return ids.EmptyNodeID, fmt.Errorf("%s has no staking cert, pass --node-id or create it with 'ggt network create': %w", workDir, err)
+	}
+	return id, nil
+}
+
+func addValidator(uri string, key *secp256k1.PrivateKey, nodeID ids.NodeID, duration time.Duration) (ids.ID, error) {
 	kc := secp256k1fx.NewKeychain(key)
 	subnetOwner := key.Address()
 	ctx := context.Background()
@@ -57,17 +79,11 @@ func addValidator(key *secp256k1.PrivateKey) (ids.ID, error) {
 		},
 	}
 
-	nodeID := "NodeID-5FKRwqFyQnZGoN7FTc3t3TUHyuUGPhBxJ"
-	nodeShortID, err := ids.NodeIDFromString(nodeID)
-	if err != nil {
-		return ids.ID{}, fmt.Errorf("error decoding nodeID %s: %w", nodeID, err)
-	}
-
 	startTime := time.Now().Add(10 * time.Second)
-	endTime := startTime.Add(24 * time.Hour)
+	endTime := startTime.Add(duration)
 
-	vdr := txs.Validator{
-		NodeID: nodeShortID,
+	vdr := validator.Validator{
+		NodeID: nodeID,
 		Start:  uint64(startTime.Unix()),
 		End:    uint64(endTime.Unix()),
 		Wght:   2 * units.KiloAvax,
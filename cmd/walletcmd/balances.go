@@ -0,0 +1,156 @@
+package walletcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/lasthyphen/dijetsnode/ids"
+	"github.com/lasthyphen/dijetsnode/utils/constants"
+	"github.com/lasthyphen/dijetsnode/utils/crypto/secp256k1"
+	"github.com/lasthyphen/dijetsnode/utils/formatting/address"
+	"github.com/lasthyphen/ecctools/pkg/configs"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/lasthyphen/utilitychain/plugin/evm"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+func newBalancesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "balances work-dir",
+		Short: "Show the P, X and C-chain AVAX balances of --pk and every user in accounts.json",
+		Long:  ``,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			uri := utils.ResolveNodeURL(args[0])
+
+			keys := map[string]*secp256k1.PrivateKey{}
+			names := []string{"pk"}
+			key, err := DecodePrivateKey(viper.GetString("pk"))
+			cobra.CheckErr(err)
+			keys["pk"] = key
+
+			if utils.FileExists(viper.GetString("accounts")) {
+				accounts, err := utils.LoadJSON(viper.GetString("accounts"))
+				cobra.CheckErr(err)
+				accounts.ForEach(func(name gjson.Result, value gjson.Result) bool {
+					key, err = DecodePrivateKey(value.Get("pk").String())
+					if err != nil {
+						err = fmt.Errorf("account %s: %w", name, err)
+						return false
+					}
+					keys[name.String()] = key
+					names = append(names, name.String())
+					return true
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			out := "{}"
+			for _, name := range names {
+				k := keys[name]
+				balances, err := getBalances(uri, k.Address(), evm.GetEthAddress(k).Hex())
+				if err != nil {
+					return err
+				}
+				b, err := json.Marshal(balances)
+				if err != nil {
+					return err
+				}
+				out, _ = sjson.SetRaw(out, name, string(b))
+			}
+			fmt.Println(out)
+			return nil
+		},
+	}
+	cmd.Flags().String("accounts", configs.AccountsFilename, "JSON file with accounts")
+	return cmd
+}
+
+// Balances of one key on the primary network chains, in AVAX
+type Balances struct {
+	PAddr string          `json:"pAddr"`
+	XAddr string          `json:"xAddr"`
+	CAddr string          `json:"cAddr"`
+	P     PBalances       `json:"P"`
+	X     decimal.Decimal `json:"X"`
+	C     decimal.Decimal `json:"C"`
+}
+
+type PBalances struct {
+	Unlocked           decimal.Decimal `json:"unlocked"`
+	LockedStakeable    decimal.Decimal `json:"lockedStakeable"`
+	LockedNotStakeable decimal.Decimal `json:"lockedNotStakeable"`
+	Staked             decimal.Decimal `json:"staked"`
+}
+
+// networkHRP is the bech32 prefix of addresses on the node's network (e.g. custom)
+func networkHRP(uri string) (string, error) {
+	result, err := utils.FetchRPCGJSON(fmt.Sprintf("%s/ext/info", uri), "info.getNetworkID", "")
+	if err != nil {
+		return "", err
+	}
+	return constants.GetHRP(uint32(result.Get("result.networkID").Uint())), nil
+}
+
+// chainAddr formats addr for chainAlias (P or X) on the node's network
+func chainAddr(uri string, chainAlias string, addr ids.ShortID) (string, error) {
+	hrp, err := networkHRP(uri)
+	if err != nil {
+		return "", err
+	}
+	return address.Format(chainAlias, hrp, addr.Bytes())
+}
+
+func getBalances(uri string, addr ids.ShortID, ethAddr string) (*Balances, error) {
+	hrp, err := networkHRP(uri)
+	if err != nil {
+		return nil, err
+	}
+	b := &Balances{CAddr: ethAddr}
+	if b.PAddr, err = address.Format("P", hrp, addr.Bytes()); err != nil {
+		return nil, err
+	}
+	if b.XAddr, err = address.Format("X", hrp, addr.Bytes()); err != nil {
+		return nil, err
+	}
+
+	urlP := fmt.Sprintf("%s/ext/bc/P", uri)
+	addrs := fmt.Sprintf(`{"addresses":["%s"]}`, b.PAddr)
+	pBalance, err := utils.FetchRPCGJSON(urlP, "platform.getBalance", addrs)
+	if err != nil {
+		return nil, err
+	}
+	b.P.Unlocked = utils.ToDecimal(pBalance.Get("result.unlocked").String(), 9)
+	b.P.LockedStakeable = utils.ToDecimal(pBalance.Get("result.lockedStakeable").String(), 9)
+	b.P.LockedNotStakeable = utils.ToDecimal(pBalance.Get("result.lockedNotStakeable").String(), 9)
+	pStake, err := utils.FetchRPCGJSON(urlP, "platform.getStake", addrs)
+	if err != nil {
+		return nil, err
+	}
+	b.P.Staked = utils.ToDecimal(pStake.Get("result.staked").String(), 9)
+
+	xBalance, err := utils.FetchRPCGJSON(fmt.Sprintf("%s/ext/bc/X", uri), "avm.getBalance", fmt.Sprintf(`{"address":"%s","assetID":"AVAX"}`, b.XAddr))
+	if err != nil {
+		return nil, err
+	}
+	b.X = utils.ToDecimal(xBalance.Get("result.balance").String(), 9)
+
+	cBalance, err := utils.FetchRPCGJSON(fmt.Sprintf("%s/ext/bc/C/rpc", uri), "eth_getBalance", fmt.Sprintf(`["%s","latest"]`, ethAddr))
+	if err != nil {
+		return nil, err
+	}
+	wei, ok := new(big.Int).SetString(cBalance.Get("result").String(), 0)
+	if !ok {
+		return nil, fmt.Errorf("unable to get C-chain balance of %s: %s", ethAddr, cBalance.Raw)
+	}
+	b.C = utils.ToDecimal(wei, 18)
+	return b, nil
+}
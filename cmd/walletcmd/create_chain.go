@@ -5,11 +5,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/lasthyphen/dijetsnode/ids"
+	"github.com/lasthyphen/dijetsnode/utils/constants"
 	"github.com/lasthyphen/dijetsnode/utils/crypto/secp256k1"
 	"github.com/lasthyphen/dijetsnode/vms/secp256k1fx"
-	"github.com/lasthyphen/dijetsnode/wallet/subnet/primary"
+	"github.com/lasthyphen/ecctools/pkg/diagnose"
+	"github.com/lasthyphen/ecctools/pkg/nodeclient"
 	"github.com/lasthyphen/ecctools/pkg/utils"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -28,7 +32,8 @@ func newCreateChainCmd() *cobra.Command {
 
 			workDir := args[0]
 			name := args[1]
-			vm := args[2]
+			vmID, err := utils.VMID(args[2])
+			cobra.CheckErr(err)
 
 			var subnetID ids.ID
 			if len(args) > 3 {
@@ -41,25 +46,16 @@ func newCreateChainCmd() *cobra.Command {
 			}
 
 			// Dont allow duplicate chain names, for simplicity
-			uri := viper.GetString("node-url")
-			urlP := fmt.Sprintf("%s/ext/bc/P", uri)
-			getBlockchains, err := utils.FetchRPCGJSON(urlP, "platform.getBlockchains", "")
+			uri := utils.ResolveNodeURL(workDir)
+			existing, err := nodeclient.New(uri).FindBlockchain(context.Background(), name)
 			cobra.CheckErr(err)
-			for _, obj := range getBlockchains.Get("result.blockchains").Array() {
-				if obj.Get("name").String() == name {
-					return fmt.Errorf("blockchain %s already exists, aborting", name)
-				}
+			if existing != nil {
+				return fmt.Errorf("blockchain %s already exists, aborting", name)
 			}
 
 			_ = viper.BindPFlags(cmd.Flags())
 
-			key, err := decodePrivateKey(viper.GetString("pk"))
-			cobra.CheckErr(err)
-
-			// Construct vm ids.ID
-			paddedBytes := [32]byte{}
-			copy(paddedBytes[:], []byte(vm))
-			vmID, err := ids.ToID(paddedBytes[:])
+			key, err := DecodePrivateKey(viper.GetString("pk"))
 			cobra.CheckErr(err)
 
 			genesisBytes, err := os.ReadFile(viper.GetString("genesis-file"))
@@ -67,45 +63,36 @@ func newCreateChainCmd() *cobra.Command {
 
 			if subnetID == ids.Empty {
 				app.Log.Info("No SubnetID supplied, creating...")
-				subnetID, err = createSubnet(key)
+				subnetID, err = CreateSubnet(uri, key)
 				cobra.CheckErr(err)
 				app.Log.Infof("SubnetID %s created", subnetID)
 			}
 
-			txID, err := createChain(key, subnetID, name, vmID, genesisBytes)
+			exportFile := viper.GetString("export")
+			txID, err := createChain(uri, key, subnetID, name, vmID, genesisBytes, exportFile)
 			cobra.CheckErr(err)
+			if exportFile != "" {
+				app.Log.Infof("Collect the remaining signatures with 'ggt wallet sign %s %s --pk ...'", workDir, exportFile)
+				app.Log.Infof("then run 'ggt wallet submit %s %s --config-file %s'", workDir, exportFile, viper.GetString("config-file"))
+				return nil
+			}
 			app.Log.Infof("Chain created with txID: %s", txID)
 
-			// Copy the chain config to the right place
-			chainConfigDir := filepath.Join(workDir, "configs", "chains", txID.String())
-			err = os.MkdirAll(chainConfigDir, os.ModePerm)
-			cobra.CheckErr(err)
-			err = utils.CopyFile(viper.GetString("config-file"), filepath.Join(chainConfigDir, "config.json"))
+			err = InstallChain(workDir, txID, name, viper.GetString("config-file"))
 			cobra.CheckErr(err)
 
-			// TODO Creating the chain alias would be nice IF I could get it to work for the RPC url too
-			// like instead of http://localhost:9650/ext/bc/ByeHH...yL9/rpc I want http://localhost:9650/ext/bc/MyChainAlias/rpc
-			// but I cant get it to work, not even sure if it is capable of working like that.
-			//
-			// Create an alias in aliases.json
-			fileLocations := utils.NewFileLocations(workDir)
-			aliasesContent, err := os.ReadFile(fileLocations.ChainAliasesFile)
-			cobra.CheckErr(err)
-			var aliasesJson string
-			aliasesJson = gjson.Parse(string(aliasesContent)).String()
-			if aliasesJson == "" {
-				app.Log.Warnf("Chain alias not created, unable to parse %s", fileLocations.ChainAliasesFile)
+			app.Log.Infof("created new blockchain %s with ID: %s", name, txID)
+			if viper.GetBool("wait") {
+				if err := waitForChain(workDir, subnetID, txID, viper.GetDuration("wait-timeout")); err != nil {
+					return err
+				}
 			} else {
-				aliasesJson, _ = sjson.Set(aliasesJson, txID.String(), []string{name})
-				_ = utils.WriteFileBytes(fileLocations.ChainAliasesFile, []byte(aliasesJson))
+				app.Log.Infof("NOTE: The blockchain may not start if anything is wrong with the VM binary or paths, run 'ggt node diagnose %s --chain %s' if it doesn't", workDir, txID)
 			}
-
-			app.Log.Infof("created new blockchain %s with ID: %s", name, txID)
-			app.Log.Info("NOTE: Check the data/logs/main.log file, as the blockchain may not start if anything is wrong with the VM binary or paths")
 			app.Log.Info("")
-			app.Log.Infof("RPC: %s/ext/bc/%s/rpc\n", primary.LocalAPIURI, txID)
+			app.Log.Infof("RPC: %s/ext/bc/%s/rpc\n", uri, txID)
 			app.Log.Info("")
-			app.Log.Info("run 'gtt node info' to see more")
+			app.Log.Infof("run 'ggt node info %s' to see more", workDir)
 
 			// Chain config doesnt get picked up until a restart happens.
 			// Update: Not sure this is true
@@ -125,29 +112,118 @@ func newCreateChainCmd() *cobra.Command {
 	}
 	cmd.Flags().String("genesis-file", "subnetevm-genesis.json", "Full path to genesis file (Defaults to subnetEVM)")
 	cmd.Flags().String("config-file", "subnetevm-config.json", "Full path to chain config file (Defaults to subnetEVM)")
+	cmd.Flags().String("export", "", "Write the partially signed tx to this file instead of issuing it (for multisig subnets)")
+	cmd.Flags().Bool("wait", false, "Wait until the chain is bootstrapped and serving RPC before returning")
+	cmd.Flags().Duration("wait-timeout", 2*time.Minute, "How long --wait waits")
 	return cmd
 }
 
-func createChain(key *secp256k1.PrivateKey, subnetID ids.ID, name string, vmID ids.ID, genesisBytes []byte) (ids.ID, error) {
-	uri := viper.GetString("node-url")
+// waitForChain waits until the node in workDir serves the new chain's RPC
+func waitForChain(workDir string, subnetID ids.ID, chainID ids.ID, timeout time.Duration) error {
+	// Without staking the node runs the chains of every subnet
+	files := utils.NewFileLocations(workDir)
+	if subnetID != constants.PrimaryNetworkID && utils.FileExists(files.StakingCertFile) {
+		tracked := false
+		if b, err := os.ReadFile(files.ConfigFile); err == nil {
+			for _, key := range []string{"track-subnets", "whitelisted-subnets"} {
+				for _, s := range strings.Split(gjson.GetBytes(b, key).String(), ",") {
+					tracked = tracked || strings.TrimSpace(s) == subnetID.String()
+				}
+			}
+		}
+		if !tracked {
+			app.Log.Warnf("The node doesn't track subnet %s, so it won't run the chain until it does (see 'ggt wallet add-subnet-validator')", subnetID)
+		}
+	}
+	app.Log.Infof("Waiting for chain %s to serve RPC...", chainID)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := nodeclient.New(utils.ResolveNodeURL(workDir)).WaitReady(ctx, chainID.String(), time.Second, nodeclient.IgnoredHealthChecks(workDir)...); err != nil {
+		findings, derr := diagnose.Chain(workDir, chainID.String())
+		if derr != nil {
+			return err
+		}
+		return fmt.Errorf("%w\n%s", err, diagnose.Format(workDir, findings))
+	}
+	return nil
+}
+
+func createChain(uri string, key *secp256k1.PrivateKey, subnetID ids.ID, name string, vmID ids.ID, genesisBytes []byte, exportFile string) (ids.ID, error) {
 	kc := secp256k1fx.NewKeychain(key)
 	ctx := context.Background()
 
-	wallet, err := primary.NewWalletWithTxs(ctx, uri, kc, subnetID)
+	wallet, err := newSubnetWallet(ctx, uri, kc, subnetID)
+	if err != nil {
+		return ids.Empty, err
+	}
+	options, err := subnetAuthOptions(ctx, uri, kc, subnetID)
 	if err != nil {
-		return ids.Empty, fmt.Errorf("failed to initialize wallet: %w", err)
+		return ids.Empty, err
 	}
 
-	createChainTxID, err := wallet.P().IssueCreateChainTx(
+	utx, err := wallet.P().Builder().NewCreateChainTx(
 		subnetID,
 		genesisBytes,
 		vmID,
 		nil,
 		name,
+		options...,
 	)
+	if err != nil {
+		return ids.Empty, fmt.Errorf("failed to build CreateBlockchainTx: %w", err)
+	}
+
+	createChainTxID, err := signAndIssue(ctx, wallet, utx, exportFile)
 	if err != nil {
 		return ids.Empty, fmt.Errorf("failed to issue CreateBlockchainTx: %w", err)
 	}
 
 	return createChainTxID, nil
 }
+
+// CreateChain issues a CreateBlockchain tx for the node in workDir and installs
+// the chain config and alias into workDir.
+func CreateChain(workDir string, key *secp256k1.PrivateKey, subnetID ids.ID, name string, vm string, genesisBytes []byte, configFile string) (ids.ID, error) {
+	vmID, err := utils.VMID(vm)
+	if err != nil {
+		return ids.Empty, err
+	}
+	uri := utils.ResolveNodeURL(workDir)
+	txID, err := createChain(uri, key, subnetID, name, vmID, genesisBytes, "")
+	if err != nil {
+		return ids.Empty, err
+	}
+	return txID, InstallChain(workDir, txID, name, configFile)
+}
+
+// InstallChain copies the chain config to the right place and creates an alias for the chain
+func InstallChain(workDir string, chainID ids.ID, name string, configFile string) error {
+	chainConfigDir := filepath.Join(workDir, "configs", "chains", chainID.String())
+	if err := os.MkdirAll(chainConfigDir, os.ModePerm); err != nil {
+		return err
+	}
+	if configFile != "" {
+		if err := utils.CopyFile(configFile, filepath.Join(chainConfigDir, "config.json")); err != nil {
+			return err
+		}
+	}
+
+	// TODO Creating the chain alias would be nice IF I could get it to work for the RPC url too
+	// like instead of http://localhost:9650/ext/bc/ByeHH...yL9/rpc I want http://localhost:9650/ext/bc/MyChainAlias/rpc
+	// but I cant get it to work, not even sure if it is capable of working like that.
+	//
+	// Create an alias in aliases.json
+	fileLocations := utils.NewFileLocations(workDir)
+	aliasesContent, err := os.ReadFile(fileLocations.ChainAliasesFile)
+	if err != nil {
+		return err
+	}
+	var aliasesJson string
+	aliasesJson = gjson.Parse(string(aliasesContent)).String()
+	if aliasesJson == "" {
+		app.Log.Warnf("Chain alias not created, unable to parse %s", fileLocations.ChainAliasesFile)
+		return nil
+	}
+	aliasesJson, _ = sjson.Set(aliasesJson, chainID.String(), []string{name})
+	return utils.WriteFileBytes(fileLocations.ChainAliasesFile, []byte(aliasesJson))
+}
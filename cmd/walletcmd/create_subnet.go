@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/lasthyphen/dijetsnode/ids"
+	avautils "github.com/lasthyphen/dijetsnode/utils"
 	"github.com/lasthyphen/dijetsnode/utils/crypto/secp256k1"
 	"github.com/lasthyphen/dijetsnode/vms/secp256k1fx"
 	"github.com/lasthyphen/dijetsnode/wallet/subnet/primary"
@@ -17,27 +18,56 @@ func newCreateSubnetCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "create-subnet work-dir",
 		Short: "Issue a CreateSubnet tx and return the txID (which is the subnetID)",
-		Long:  ``,
-		Args:  cobra.ExactArgs(1),
+		Long: `Creates a subnet owned by --pk, or by --control-keys (P-chain addresses) of
+which --threshold have to sign every subnet tx, e.g.
+
+  ggt wallet create-subnet Node1 --threshold 2 \
+    --control-keys P-custom1...,P-custom1...,P-custom1...`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
 			if exists := utils.DirExists(args[0]); !exists {
 				return fmt.Errorf("node directory does not exist: %s", args[0])
 			}
-			key, err := decodePrivateKey(viper.GetString("pk"))
+			key, err := DecodePrivateKey(viper.GetString("pk"))
 			cobra.CheckErr(err)
-			txID, err := createSubnet(key)
+
+			controlKeys := []ids.ShortID{key.Address()}
+			if len(viper.GetStringSlice("control-keys")) > 0 {
+				controlKeys, err = parseAddrs(viper.GetStringSlice("control-keys"))
+				if err != nil {
+					return err
+				}
+			}
+
+			txID, err := CreateMultisigSubnet(utils.ResolveNodeURL(args[0]), key, controlKeys, viper.GetUint32("threshold"))
 			cobra.CheckErr(err)
 			fmt.Println(txID)
 			return nil
 		},
 	}
+	cmd.Flags().StringSlice("control-keys", nil, "P-chain addresses that control the subnet (default is the address of --pk)")
+	cmd.Flags().Uint32("threshold", 1, "Number of control keys that must sign subnet txs")
 	return cmd
 }
 
-func createSubnet(key *secp256k1.PrivateKey) (ids.ID, error) {
-	uri := viper.GetString("node-url")
+// CreateSubnet creates a subnet controlled by key alone
+func CreateSubnet(uri string, key *secp256k1.PrivateKey) (ids.ID, error) {
+	return CreateMultisigSubnet(uri, key, []ids.ShortID{key.Address()}, 1)
+}
+
+// CreateMultisigSubnet creates a subnet controlled by threshold of controlKeys, paying the fee with key
+func CreateMultisigSubnet(uri string, key *secp256k1.PrivateKey, controlKeys []ids.ShortID, threshold uint32) (ids.ID, error) {
+	addrs := append([]ids.ShortID{}, controlKeys...)
+	avautils.Sort(addrs)
+	if !avautils.IsSortedAndUniqueSortable(addrs) {
+		return ids.Empty, fmt.Errorf("duplicate control keys")
+	}
+	if threshold == 0 || int(threshold) > len(addrs) {
+		return ids.Empty, fmt.Errorf("threshold must be between 1 and %d", len(addrs))
+	}
+
 	kc := secp256k1fx.NewKeychain(key)
-	subnetOwner := key.Address()
 	ctx := context.Background()
 
 	wallet, err := primary.NewWalletFromURI(ctx, uri, kc)
@@ -46,10 +76,8 @@ func createSubnet(key *secp256k1.PrivateKey) (ids.ID, error) {
 	}
 
 	owner := &secp256k1fx.OutputOwners{
-		Threshold: 1,
-		Addrs: []ids.ShortID{
-			subnetOwner,
-		},
+		Threshold: threshold,
+		Addrs:     addrs,
 	}
 
 	createSubnetTxID, err := wallet.P().IssueCreateSubnetTx(owner)
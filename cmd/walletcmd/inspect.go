@@ -0,0 +1,37 @@
+package walletcmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lasthyphen/dijetsnode/api/info"
+	"github.com/lasthyphen/dijetsnode/ids"
+	"github.com/lasthyphen/dijetsnode/snow"
+	"github.com/lasthyphen/dijetsnode/utils/constants"
+)
+
+// jsonContext is the minimal snow.Context that txs and UTXOs need to marshal
+// their addresses as chainAlias-hrp1... instead of failing.
+func jsonContext(ctx context.Context, uri string, chainAlias string) (*snow.Context, error) {
+	infoClient := info.NewClient(uri)
+	networkID, err := infoClient.GetNetworkID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	chainID := constants.PlatformChainID
+	if chainAlias != "P" {
+		chainID, err = infoClient.GetBlockchainID(ctx, chainAlias)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get %s-chain ID: %w", chainAlias, err)
+		}
+	}
+	aliaser := ids.NewAliaser()
+	if err := aliaser.Alias(chainID, chainAlias); err != nil {
+		return nil, err
+	}
+	return &snow.Context{
+		NetworkID: networkID,
+		ChainID:   chainID,
+		BCLookup:  aliaser,
+	}, nil
+}
@@ -0,0 +1,117 @@
+package walletcmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lasthyphen/dijetsnode/ids"
+	"github.com/lasthyphen/dijetsnode/utils/crypto/secp256k1"
+	"github.com/lasthyphen/dijetsnode/vms/platformvm"
+	"github.com/lasthyphen/dijetsnode/vms/platformvm/txs"
+	"github.com/lasthyphen/dijetsnode/vms/platformvm/validator"
+	"github.com/lasthyphen/dijetsnode/vms/secp256k1fx"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+)
+
+// ChainRecord is what it takes to create a chain again on a fresh network
+type ChainRecord struct {
+	ID       ids.ID
+	Name     string
+	VMID     ids.ID
+	Genesis  []byte
+	SubnetID ids.ID
+	Owner    *secp256k1fx.OutputOwners
+}
+
+// GetChainRecord reads chainID's CreateChainTx, and the owner of its subnet, from the P-chain
+func GetChainRecord(ctx context.Context, uri string, chainID ids.ID) (*ChainRecord, error) {
+	txBytes, err := platformvm.NewClient(uri).GetTx(ctx, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch chain %s: %w", chainID, err)
+	}
+	tx, err := txs.Parse(txs.Codec, txBytes)
+	if err != nil {
+		return nil, err
+	}
+	chainTx, ok := tx.Unsigned.(*txs.CreateChainTx)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a chain", chainID)
+	}
+	owner, err := subnetOwner(ctx, uri, chainTx.SubnetID)
+	if err != nil {
+		return nil, err
+	}
+	return &ChainRecord{
+		ID:       chainID,
+		Name:     chainTx.ChainName,
+		VMID:     chainTx.VMID,
+		Genesis:  chainTx.GenesisData,
+		SubnetID: chainTx.SubnetID,
+		Owner:    owner,
+	}, nil
+}
+
+// RecreateChains creates a new subnet, with the same owner, for each subnet in
+// chains and then the chains in it, installing them into workDir with the
+// config file in configFiles (if any). The node is added as a validator of, and
+// tracks, each new subnet that replaces one in tracked. Returns old ID => new ID
+// for subnets and chains.
+func RecreateChains(workDir string, key *secp256k1.PrivateKey, chains []ChainRecord, configFiles map[ids.ID]string, tracked map[ids.ID]bool) (map[ids.ID]ids.ID, error) {
+	uri := utils.ResolveNodeURL(workDir)
+	replaced := map[ids.ID]ids.ID{}
+	for _, c := range chains {
+		subnetID, ok := replaced[c.SubnetID]
+		if !ok {
+			var err error
+			if subnetID, err = CreateMultisigSubnet(uri, key, c.Owner.Addrs, c.Owner.Threshold); err != nil {
+				return replaced, fmt.Errorf("unable to recreate subnet %s: %w", c.SubnetID, err)
+			}
+			replaced[c.SubnetID] = subnetID
+			app.Log.Infof("Recreated subnet %s as %s", c.SubnetID, subnetID)
+			if tracked[c.SubnetID] {
+				if err := validateSubnet(workDir, key, subnetID); err != nil {
+					app.Log.Warnf("Unable to add the node as a validator of %s: %s", subnetID, err)
+				}
+				if _, err := TrackSubnet(workDir, subnetID); err != nil {
+					return replaced, err
+				}
+			}
+		}
+
+		chainID, err := createChain(uri, key, subnetID, c.Name, c.VMID, c.Genesis, "")
+		if err != nil {
+			return replaced, fmt.Errorf("unable to recreate chain %s (%s): %w", c.Name, c.ID, err)
+		}
+		if err := InstallChain(workDir, chainID, c.Name, configFiles[c.ID]); err != nil {
+			return replaced, err
+		}
+		replaced[c.ID] = chainID
+		app.Log.Infof("Recreated chain %s (%s) as %s", c.Name, c.ID, chainID)
+	}
+	return replaced, nil
+}
+
+// validateSubnet adds the node in workDir as a validator of subnetID for as long as it validates the primary network
+func validateSubnet(workDir string, key *secp256k1.PrivateKey, subnetID ids.ID) error {
+	uri := utils.ResolveNodeURL(workDir)
+	nodeID, err := validatorNodeID(workDir, "")
+	if err != nil {
+		return err
+	}
+	end, err := primaryValidatorEnd(uri, nodeID)
+	if err != nil {
+		return err
+	}
+	vdr := &validator.SubnetValidator{
+		Validator: validator.Validator{
+			NodeID: nodeID,
+			Start:  uint64(time.Now().Add(30 * time.Second).Unix()),
+			End:    uint64(end.Unix()),
+			Wght:   20,
+		},
+		Subnet: subnetID,
+	}
+	_, err = addSubnetValidator(uri, key, vdr, "")
+	return err
+}
@@ -0,0 +1,56 @@
+package walletcmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lasthyphen/dijetsnode/vms/secp256k1fx"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newSignCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sign work-dir tx-file",
+		Short: "Add the --pk signature to a tx written by --export",
+		Long:  ``,
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			workDir, fn := args[0], args[1]
+			key, err := DecodePrivateKey(viper.GetString("pk"))
+			cobra.CheckErr(err)
+			tx, err := loadTx(fn)
+			if err != nil {
+				return err
+			}
+			before := missingSigs(tx)
+
+			kc := secp256k1fx.NewKeychain(key)
+			ctx := context.Background()
+			subnetID, _ := txSubnetID(tx)
+			wallet, err := newSubnetWallet(ctx, utils.ResolveNodeURL(workDir), kc, subnetID)
+			if err != nil {
+				return err
+			}
+			if err := wallet.P().Signer().Sign(ctx, tx); err != nil {
+				return fmt.Errorf("failed to sign tx: %w", err)
+			}
+
+			after := missingSigs(tx)
+			if after == before {
+				return fmt.Errorf("%s can't sign any of the missing signatures", key.Address())
+			}
+			if err := saveTx(fn, tx); err != nil {
+				return err
+			}
+			if after > 0 {
+				app.Log.Infof("Signed %s, it needs %d more signatures", fn, after)
+			} else {
+				app.Log.Infof("Signed %s, run 'ggt wallet submit %s %s' to issue it", fn, workDir, fn)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
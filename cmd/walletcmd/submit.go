@@ -0,0 +1,73 @@
+package walletcmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lasthyphen/dijetsnode/ids"
+	"github.com/lasthyphen/dijetsnode/vms/platformvm/txs"
+	"github.com/lasthyphen/dijetsnode/vms/secp256k1fx"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newSubmitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "submit work-dir tx-file",
+		Short: "Issue a fully signed tx written by --export and return the txID",
+		Long: `Issues the tx through the node in work-dir. Afterwards a new chain is installed
+in work-dir like 'wallet create-chain' does, and if work-dir is the new subnet
+validator the node is set up to track the subnet.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			workDir, fn := args[0], args[1]
+			tx, err := loadTx(fn)
+			if err != nil {
+				return err
+			}
+			if n := missingSigs(tx); n > 0 {
+				return fmt.Errorf("%s needs %d more signatures", fn, n)
+			}
+
+			key, err := DecodePrivateKey(viper.GetString("pk"))
+			cobra.CheckErr(err)
+			ctx := context.Background()
+			wallet, err := newSubnetWallet(ctx, utils.ResolveNodeURL(workDir), secp256k1fx.NewKeychain(key), ids.Empty)
+			if err != nil {
+				return err
+			}
+			txID, err := wallet.P().IssueTx(tx)
+			if err != nil {
+				return fmt.Errorf("failed to issue tx: %w", err)
+			}
+
+			switch utx := tx.Unsigned.(type) {
+			case *txs.CreateChainTx:
+				if err := InstallChain(workDir, txID, utx.ChainName, viper.GetString("config-file")); err != nil {
+					return err
+				}
+				app.Log.Infof("created new blockchain %s with ID: %s", utx.ChainName, txID)
+			case *txs.AddSubnetValidatorTx:
+				nodeID, err := utils.NodeID(workDir)
+				if err != nil || nodeID != utx.Validator.NodeID {
+					break
+				}
+				changed, err := TrackSubnet(workDir, utx.Validator.Subnet)
+				if err != nil {
+					return err
+				}
+				if changed {
+					if err := restartIfRunning(workDir); err != nil {
+						return err
+					}
+				}
+			}
+			fmt.Println(txID)
+			return nil
+		},
+	}
+	cmd.Flags().String("config-file", "", "Chain config file to install for a CreateBlockchain tx")
+	return cmd
+}
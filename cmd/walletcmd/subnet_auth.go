@@ -0,0 +1,182 @@
+package walletcmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/lasthyphen/dijetsnode/ids"
+	"github.com/lasthyphen/dijetsnode/utils/formatting"
+	"github.com/lasthyphen/dijetsnode/utils/formatting/address"
+	"github.com/lasthyphen/dijetsnode/utils/set"
+	"github.com/lasthyphen/dijetsnode/vms/platformvm"
+	"github.com/lasthyphen/dijetsnode/vms/platformvm/txs"
+	"github.com/lasthyphen/dijetsnode/vms/secp256k1fx"
+	"github.com/lasthyphen/dijetsnode/wallet/subnet/primary"
+	"github.com/lasthyphen/dijetsnode/wallet/subnet/primary/common"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+)
+
+// Subnet txs need signatures from threshold of the subnet's control keys, which
+// may belong to different people. Those txs can be built and partially signed
+// with --export, passed around with 'wallet sign' and issued with 'wallet submit'.
+
+// txFile is the JSON written by --export
+type txFile struct {
+	TxID string `json:"txID"`
+	Type string `json:"type"`
+	Tx   string `json:"tx"`
+}
+
+func saveTx(fn string, tx *txs.Tx) error {
+	txHex, err := formatting.Encode(formatting.Hex, tx.Bytes())
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(txFile{
+		TxID: tx.ID().String(),
+		Type: reflect.TypeOf(tx.Unsigned).Elem().Name(),
+		Tx:   txHex,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileBytes(fn, b)
+}
+
+func loadTx(fn string) (*txs.Tx, error) {
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	f := txFile{}
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", fn, err)
+	}
+	txBytes, err := formatting.Decode(formatting.Hex, f.Tx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode tx in %s: %w", fn, err)
+	}
+	return txs.Parse(txs.Codec, txBytes)
+}
+
+// missingSigs counts the signatures the tx still needs
+func missingSigs(tx *txs.Tx) int {
+	missing := 0
+	for _, credIntf := range tx.Creds {
+		cred, ok := credIntf.(*secp256k1fx.Credential)
+		if !ok {
+			continue
+		}
+		for _, sig := range cred.Sigs {
+			if bytes.Equal(sig[:], make([]byte, len(sig))) {
+				missing++
+			}
+		}
+	}
+	return missing
+}
+
+// txSubnetID returns the subnet a subnet-authorized tx acts on
+func txSubnetID(tx *txs.Tx) (ids.ID, bool) {
+	switch utx := tx.Unsigned.(type) {
+	case *txs.CreateChainTx:
+		return utx.SubnetID, true
+	case *txs.AddSubnetValidatorTx:
+		return utx.Validator.Subnet, true
+	case *txs.RemoveSubnetValidatorTx:
+		return utx.Subnet, true
+	case *txs.TransformSubnetTx:
+		return utx.Subnet, true
+	}
+	return ids.Empty, false
+}
+
+// newSubnetWallet returns a wallet that knows about subnetID (if any) so it can sign for it
+func newSubnetWallet(ctx context.Context, uri string, kc *secp256k1fx.Keychain, subnetID ids.ID) (primary.Wallet, error) {
+	preload := []ids.ID{}
+	if subnetID != ids.Empty {
+		preload = append(preload, subnetID)
+	}
+	wallet, err := primary.NewWalletWithTxs(ctx, uri, kc, preload...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize wallet: %w", err)
+	}
+	return wallet, nil
+}
+
+// subnetAuthOptions let the builder authorize the subnet with control keys that
+// are not in kc (they sign later), while fees and change stay with kc.
+func subnetAuthOptions(ctx context.Context, uri string, kc *secp256k1fx.Keychain, subnetID ids.ID) ([]common.Option, error) {
+	owner, err := subnetOwner(ctx, uri, subnetID)
+	if err != nil {
+		return nil, err
+	}
+	payer := kc.Addresses().List()[0]
+	addrs := set.NewSet[ids.ShortID](len(owner.Addrs) + 1)
+	addrs.Add(payer)
+	addrs.Add(owner.Addrs...)
+	return []common.Option{
+		common.WithCustomAddresses(addrs),
+		common.WithChangeOwner(&secp256k1fx.OutputOwners{
+			Threshold: 1,
+			Addrs:     []ids.ShortID{payer},
+		}),
+	}, nil
+}
+
+func subnetOwner(ctx context.Context, uri string, subnetID ids.ID) (*secp256k1fx.OutputOwners, error) {
+	txBytes, err := platformvm.NewClient(uri).GetTx(ctx, subnetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch subnet %s: %w", subnetID, err)
+	}
+	tx, err := txs.Parse(txs.Codec, txBytes)
+	if err != nil {
+		return nil, err
+	}
+	subnetTx, ok := tx.Unsigned.(*txs.CreateSubnetTx)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a subnet", subnetID)
+	}
+	owner, ok := subnetTx.Owner.(*secp256k1fx.OutputOwners)
+	if !ok {
+		return nil, fmt.Errorf("subnet %s has an unknown owner type", subnetID)
+	}
+	return owner, nil
+}
+
+// signAndIssue signs utx with the wallet's keys. If exportFile is set the
+// (possibly partially) signed tx is written there instead of being issued.
+func signAndIssue(ctx context.Context, wallet primary.Wallet, utx txs.UnsignedTx, exportFile string) (ids.ID, error) {
+	tx, err := wallet.P().Signer().SignUnsigned(ctx, utx)
+	if err != nil {
+		return ids.Empty, fmt.Errorf("failed to sign tx: %w", err)
+	}
+	if exportFile != "" {
+		if err := saveTx(exportFile, tx); err != nil {
+			return ids.Empty, err
+		}
+		app.Log.Infof("Wrote tx %s to %s, it needs %d more signatures", tx.ID(), exportFile, missingSigs(tx))
+		return tx.ID(), nil
+	}
+	if n := missingSigs(tx); n > 0 {
+		return ids.Empty, fmt.Errorf("tx needs %d more signatures, use --export and 'ggt wallet sign'", n)
+	}
+	return wallet.P().IssueTx(tx)
+}
+
+// parseAddrs parses P-chain addresses like P-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p
+func parseAddrs(addrs []string) ([]ids.ShortID, error) {
+	out := []ids.ShortID{}
+	for _, a := range addrs {
+		id, err := address.ParseToID(a)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %s: %w", a, err)
+		}
+		out = append(out, id)
+	}
+	return out, nil
+}
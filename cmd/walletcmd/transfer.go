@@ -0,0 +1,304 @@
+package walletcmd
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lasthyphen/dijetsnode/api"
+	"github.com/lasthyphen/dijetsnode/api/info"
+	"github.com/lasthyphen/dijetsnode/api/keystore"
+	"github.com/lasthyphen/dijetsnode/ids"
+	"github.com/lasthyphen/dijetsnode/utils/constants"
+	"github.com/lasthyphen/dijetsnode/utils/crypto/secp256k1"
+	"github.com/lasthyphen/dijetsnode/vms/avm"
+	"github.com/lasthyphen/dijetsnode/vms/components/avax"
+	"github.com/lasthyphen/dijetsnode/vms/platformvm"
+	"github.com/lasthyphen/dijetsnode/vms/platformvm/txs"
+	"github.com/lasthyphen/dijetsnode/vms/secp256k1fx"
+	"github.com/lasthyphen/dijetsnode/wallet/chain/x"
+	"github.com/lasthyphen/dijetsnode/wallet/subnet/primary"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/lasthyphen/utilitychain/plugin/evm"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newTransferCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "transfer work-dir amount",
+		Short: "Move AVAX between the X, P and C chains",
+		Long: `Exports amount AVAX (e.g. 10 or 0.5) from --from-chain and imports it on
+--to-chain, waiting for both txs to be accepted, then prints the balances.
+
+Funds are imported to --to, which defaults to the --pk key's own address.
+Use a P-/X- address for the P and X chains and a 0x address for the C-chain.
+
+X and P txs are built and signed locally. The C-chain side goes through the
+node's keystore API, using a temporary user that is deleted afterwards.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			if exists := utils.DirExists(args[0]); !exists {
+				return fmt.Errorf("node directory does not exist: %s", args[0])
+			}
+			amount, err := parseAVAX(args[1])
+			if err != nil {
+				return err
+			}
+			from := strings.ToUpper(viper.GetString("from-chain"))
+			to := strings.ToUpper(viper.GetString("to-chain"))
+			if !validChain(from) || !validChain(to) || from == to {
+				return fmt.Errorf("--from-chain and --to-chain must be two different chains out of X, P and C")
+			}
+			key, err := DecodePrivateKey(viper.GetString("pk"))
+			cobra.CheckErr(err)
+
+			uri := utils.ResolveNodeURL(args[0])
+			t, err := newTransfer(uri, key)
+			if err != nil {
+				return err
+			}
+			defer t.close()
+
+			if err := t.run(from, to, amount, viper.GetString("to")); err != nil {
+				return err
+			}
+
+			balances, err := getBalances(uri, key.Address(), t.ethAddr)
+			if err != nil {
+				return err
+			}
+			b, err := json.Marshal(balances)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		},
+	}
+	cmd.Flags().String("from-chain", "X", "Chain to export from (X, P or C)")
+	cmd.Flags().String("to-chain", "P", "Chain to import to (X, P or C)")
+	cmd.Flags().String("to", "", "Address to import to (default is the address of --pk)")
+	return cmd
+}
+
+func validChain(alias string) bool {
+	return alias == "X" || alias == "P" || alias == "C"
+}
+
+// parseAVAX converts a decimal AVAX amount to nAVAX
+func parseAVAX(s string) (uint64, error) {
+	d, err := decimal.NewFromString(strings.TrimSuffix(strings.ToLower(s), "avax"))
+	if err != nil || !d.IsPositive() {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	n := d.Shift(9)
+	if !n.IsInteger() || !n.BigInt().IsUint64() {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return n.BigInt().Uint64(), nil
+}
+
+type transfer struct {
+	ctx      context.Context
+	uri      string
+	key      *secp256k1.PrivateKey
+	kc       *secp256k1fx.Keychain
+	ethAddr  string
+	chainIDs map[string]ids.ID
+	user     *api.UserPass
+}
+
+func newTransfer(uri string, key *secp256k1.PrivateKey) (*transfer, error) {
+	ctx := context.Background()
+	t := &transfer{
+		ctx:      ctx,
+		uri:      uri,
+		key:      key,
+		kc:       secp256k1fx.NewKeychain(key),
+		ethAddr:  evm.GetEthAddress(key).Hex(),
+		chainIDs: map[string]ids.ID{"P": constants.PlatformChainID},
+	}
+	infoClient := info.NewClient(uri)
+	for _, alias := range []string{"X", "C"} {
+		id, err := infoClient.GetBlockchainID(ctx, alias)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get %s-chain ID: %w", alias, err)
+		}
+		t.chainIDs[alias] = id
+	}
+	return t, nil
+}
+
+func (t *transfer) run(from string, to string, amount uint64, toAddr string) error {
+	app.Log.Infof("Exporting %d nAVAX from %s to %s...", amount, from, to)
+	txID, err := t.export(from, to, amount)
+	if err != nil {
+		return fmt.Errorf("export from %s failed: %w", from, err)
+	}
+	app.Log.Infof("Export %s accepted", txID)
+
+	app.Log.Infof("Importing on %s...", to)
+	txID, err = t.importFunds(from, to, toAddr)
+	if err != nil {
+		return fmt.Errorf("import to %s failed: %w", to, err)
+	}
+	app.Log.Infof("Import %s accepted", txID)
+	return nil
+}
+
+// export sends amount from one chain to our own address in the shared memory of the other
+func (t *transfer) export(from string, to string, amount uint64) (ids.ID, error) {
+	if from == "C" {
+		user, err := t.keystoreUser()
+		if err != nil {
+			return ids.Empty, err
+		}
+		dest, err := chainAddr(t.uri, to, t.key.Address())
+		if err != nil {
+			return ids.Empty, err
+		}
+		client := evm.NewCChainClient(t.uri)
+		txID, err := client.ExportAVAX(t.ctx, *user, amount, dest)
+		if err != nil {
+			return ids.Empty, err
+		}
+		return txID, t.waitForAtomicTx(client, txID)
+	}
+
+	wallet, err := primary.NewWalletFromURI(t.ctx, t.uri, t.kc)
+	if err != nil {
+		return ids.Empty, fmt.Errorf("failed to initialize wallet: %w", err)
+	}
+	outputs := []*avax.TransferableOutput{{
+		Asset: avax.Asset{ID: wallet.X().AVAXAssetID()},
+		Out: &secp256k1fx.TransferOutput{
+			Amt:          amount,
+			OutputOwners: *t.owner(t.key.Address()),
+		},
+	}}
+	if from == "X" {
+		return wallet.X().IssueExportTx(t.chainIDs[to], outputs)
+	}
+	return wallet.P().IssueExportTx(t.chainIDs[to], outputs)
+}
+
+// importFunds imports everything exported to us from the other chain to toAddr
+func (t *transfer) importFunds(from string, to string, toAddr string) (ids.ID, error) {
+	if to == "C" {
+		if toAddr == "" {
+			toAddr = t.ethAddr
+		}
+		user, err := t.keystoreUser()
+		if err != nil {
+			return ids.Empty, err
+		}
+		client := evm.NewCChainClient(t.uri)
+		txID, err := client.Import(t.ctx, *user, toAddr, from)
+		if err != nil {
+			return ids.Empty, err
+		}
+		return txID, t.waitForAtomicTx(client, txID)
+	}
+
+	owner := t.owner(t.key.Address())
+	if toAddr != "" {
+		addrs, err := parseAddrs([]string{toAddr})
+		if err != nil {
+			return ids.Empty, err
+		}
+		owner = t.owner(addrs[0])
+	}
+
+	wallet, err := t.walletWithAtomicUTXOs(from, to)
+	if err != nil {
+		return ids.Empty, err
+	}
+	if to == "X" {
+		return wallet.X().IssueImportTx(t.chainIDs[from], owner)
+	}
+	return wallet.P().IssueImportTx(t.chainIDs[from], owner)
+}
+
+// walletWithAtomicUTXOs also loads UTXOs exported from the C-chain, which the
+// primary wallet doesn't know about.
+func (t *transfer) walletWithAtomicUTXOs(from string, to string) (primary.Wallet, error) {
+	pCTX, xCTX, utxos, err := primary.FetchState(t.ctx, t.uri, t.kc.Addresses())
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize wallet: %w", err)
+	}
+	if from == "C" {
+		var client primary.UTXOClient = platformvm.NewClient(t.uri)
+		codec := txs.Codec
+		if to == "X" {
+			client = avm.NewClient(t.uri, "X")
+			codec = x.Parser.Codec()
+		}
+		if err := primary.AddAllUTXOs(t.ctx, utxos, client, codec, t.chainIDs["C"], t.chainIDs[to], t.kc.Addresses().List()); err != nil {
+			return nil, err
+		}
+	}
+	return primary.NewWalletWithState(t.uri, pCTX, xCTX, utxos, t.kc), nil
+}
+
+func (t *transfer) owner(addr ids.ShortID) *secp256k1fx.OutputOwners {
+	return &secp256k1fx.OutputOwners{
+		Threshold: 1,
+		Addrs:     []ids.ShortID{addr},
+	}
+}
+
+// keystoreUser creates a temporary keystore user holding our key, for the C-chain APIs
+func (t *transfer) keystoreUser() (*api.UserPass, error) {
+	if t.user != nil {
+		return t.user, nil
+	}
+	secret := make([]byte, 16)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	user := api.UserPass{
+		Username: fmt.Sprintf("ggt-transfer-%x", secret[:4]),
+		Password: fmt.Sprintf("Ggt-%x!", secret),
+	}
+	if err := keystore.NewClient(t.uri).CreateUser(t.ctx, user); err != nil { //nolint:all
+		return nil, fmt.Errorf("unable to create keystore user (is the keystore API enabled?): %w", err)
+	}
+	t.user = &user
+	if _, err := evm.NewCChainClient(t.uri).ImportKey(t.ctx, user, t.key); err != nil {
+		return nil, fmt.Errorf("unable to import key into keystore: %w", err)
+	}
+	return t.user, nil
+}
+
+func (t *transfer) close() {
+	if t.user == nil {
+		return
+	}
+	if err := keystore.NewClient(t.uri).DeleteUser(t.ctx, *t.user); err != nil { //nolint:all
+		app.Log.Warnf("Unable to delete keystore user %s: %s", t.user.Username, err)
+	}
+}
+
+func (t *transfer) waitForAtomicTx(client evm.Client, txID ids.ID) error {
+	deadline := time.Now().Add(time.Minute)
+	for time.Now().Before(deadline) {
+		status, err := client.GetAtomicTxStatus(t.ctx, txID)
+		if err != nil {
+			return err
+		}
+		switch status {
+		case evm.Accepted:
+			return nil
+		case evm.Dropped:
+			return fmt.Errorf("tx %s was dropped", txID)
+		}
+		time.Sleep(time.Second)
+	}
+	return fmt.Errorf("timed out waiting for tx %s", txID)
+}
@@ -0,0 +1,94 @@
+package walletcmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/lasthyphen/dijetsnode/ids"
+	"github.com/lasthyphen/dijetsnode/vms/avm"
+	"github.com/lasthyphen/dijetsnode/vms/platformvm"
+	"github.com/lasthyphen/dijetsnode/vms/platformvm/txs"
+	"github.com/lasthyphen/dijetsnode/wallet/chain/x"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newTxCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tx work-dir txID",
+		Short: "Fetch a P- or X-chain tx and print it as JSON",
+		Long:  ``,
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			txID, err := ids.FromString(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid txID %s: %w", args[1], err)
+			}
+			uri := utils.ResolveNodeURL(args[0])
+
+			chains := []string{"P", "X"}
+			if c := strings.ToUpper(viper.GetString("chain")); c != "" {
+				chains = []string{c}
+			}
+			for _, chainAlias := range chains {
+				out, err := getTx(uri, chainAlias, txID)
+				if err != nil {
+					app.Log.Debugf("%s not found on %s: %s", txID, chainAlias, err)
+					continue
+				}
+				fmt.Println(string(out))
+				return nil
+			}
+			return fmt.Errorf("tx %s not found on %s", txID, strings.Join(chains, " or "))
+		},
+	}
+	cmd.Flags().String("chain", "", "Chain the tx is on, P or X (default is to try both)")
+	return cmd
+}
+
+// getTx fetches txID from the chain and decodes it with the chain's codec
+func getTx(uri string, chainAlias string, txID ids.ID) ([]byte, error) {
+	ctx := context.Background()
+	snowCtx, err := jsonContext(ctx, uri, chainAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	var out interface{}
+	switch chainAlias {
+	case "P":
+		txBytes, err := platformvm.NewClient(uri).GetTx(ctx, txID)
+		if err != nil {
+			return nil, err
+		}
+		tx, err := txs.Parse(txs.Codec, txBytes)
+		if err != nil {
+			return nil, err
+		}
+		tx.Unsigned.InitCtx(snowCtx)
+		out = tx
+	case "X":
+		txBytes, err := avm.NewClient(uri, "X").GetTx(ctx, txID)
+		if err != nil {
+			return nil, err
+		}
+		tx, err := x.Parser.ParseTx(txBytes)
+		if err != nil {
+			return nil, err
+		}
+		tx.Unsigned.InitCtx(snowCtx)
+		out = tx
+	default:
+		return nil, fmt.Errorf("unsupported chain %s, only P and X txs can be decoded", chainAlias)
+	}
+
+	return json.MarshalIndent(map[string]interface{}{
+		"txID":  txID,
+		"chain": chainAlias,
+		"tx":    out,
+	}, "", "  ")
+}
@@ -0,0 +1,103 @@
+package walletcmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/lasthyphen/dijetsnode/codec"
+	"github.com/lasthyphen/dijetsnode/snow"
+	"github.com/lasthyphen/dijetsnode/utils/formatting/address"
+	"github.com/lasthyphen/dijetsnode/vms/avm"
+	"github.com/lasthyphen/dijetsnode/vms/components/avax"
+	"github.com/lasthyphen/dijetsnode/vms/platformvm"
+	"github.com/lasthyphen/dijetsnode/vms/platformvm/txs"
+	"github.com/lasthyphen/dijetsnode/wallet/chain/x"
+	"github.com/lasthyphen/dijetsnode/wallet/subnet/primary"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newUTXOsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "utxos work-dir addr",
+		Short: "List the UTXOs of a P- or X-chain address",
+		Long: `Lists the UTXOs owned by addr (e.g. P-custom1...) on the chain in its prefix.
+Use --source-chain to list atomic UTXOs exported to that chain but not imported yet.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			uri := utils.ResolveNodeURL(args[0])
+			chainAlias, _, _, err := address.Parse(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid address %s: %w", args[1], err)
+			}
+			chainAlias = strings.ToUpper(chainAlias)
+			sourceAlias := strings.ToUpper(viper.GetString("source-chain"))
+			if sourceAlias == "" {
+				sourceAlias = chainAlias
+			}
+
+			out, err := listUTXOs(uri, args[1], chainAlias, sourceAlias)
+			if err != nil {
+				return err
+			}
+			b, err := json.MarshalIndent(out, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		},
+	}
+	cmd.Flags().String("source-chain", "", "List atomic UTXOs exported from this chain (X, P or C)")
+	return cmd
+}
+
+func listUTXOs(uri string, addr string, chainAlias string, sourceAlias string) ([]*avax.UTXO, error) {
+	ctx := context.Background()
+	addrs, err := parseAddrs([]string{addr})
+	if err != nil {
+		return nil, err
+	}
+	snowCtx, err := jsonContext(ctx, uri, chainAlias)
+	if err != nil {
+		return nil, err
+	}
+	sourceID := snowCtx.ChainID
+	if sourceAlias != chainAlias {
+		sourceCtx, err := jsonContext(ctx, uri, sourceAlias)
+		if err != nil {
+			return nil, err
+		}
+		sourceID = sourceCtx.ChainID
+	}
+
+	var client primary.UTXOClient
+	var c codec.Manager
+	switch chainAlias {
+	case "P":
+		client, c = platformvm.NewClient(uri), txs.Codec
+	case "X":
+		client, c = avm.NewClient(uri, "X"), x.Parser.Codec()
+	default:
+		return nil, fmt.Errorf("only P and X chain addresses are supported")
+	}
+
+	utxos := primary.NewUTXOs()
+	if err := primary.AddAllUTXOs(ctx, utxos, client, c, sourceID, snowCtx.ChainID, addrs); err != nil {
+		return nil, err
+	}
+	list, err := utxos.UTXOs(ctx, sourceID, snowCtx.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	for _, utxo := range list {
+		if out, ok := utxo.Out.(snow.ContextInitializable); ok {
+			out.InitCtx(snowCtx)
+		}
+	}
+	return list, nil
+}
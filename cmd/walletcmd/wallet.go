@@ -1,6 +1,7 @@
 package walletcmd
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strings"
@@ -16,6 +17,9 @@ var app *application.GoGoTools
 var pkStr string
 var keyFactory = new(secp256k1.Factory)
 
+// DefaultPrivateKey is the key funded by the genesis of 'local' and 'custom' (ANR) networks
+const DefaultPrivateKey = "PrivateKey-ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN"
+
 var (
 	ErrInvalidType = errors.New("invalid type")
 	ErrCantSpend   = errors.New("can't spend")
@@ -40,19 +44,29 @@ func NewCmd(injectedApp *application.GoGoTools) *cobra.Command {
 	// PrivateKey-ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN => P-local18jma8ppw3nhx5r4ap8clazz0dps7rv5u00z96u
 	// PrivateKey-ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN => P-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p
 	// 56289e99c94b6912bfc12adc093c9b51124f0dc54ac7a766b2bc5ccf558d8027 => 0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC
-	cmd.PersistentFlags().StringVar(&pkStr, "pk", "PrivateKey-ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN", "Private key")
+	cmd.PersistentFlags().StringVar(&pkStr, "pk", DefaultPrivateKey, "Private key")
 	_ = viper.BindPFlag("pk", cmd.PersistentFlags().Lookup("pk"))
 
 	cmd.AddCommand(newCreateSubnetCmd())
 	cmd.AddCommand(newCreateChainCmd())
 	cmd.AddCommand(newAddValidatorCmd())
+	cmd.AddCommand(newAddSubnetValidatorCmd())
+	cmd.AddCommand(newSignCmd())
+	cmd.AddCommand(newSubmitCmd())
+	cmd.AddCommand(newTransferCmd())
+	cmd.AddCommand(newBalancesCmd())
+	cmd.AddCommand(newUTXOsCmd())
+	cmd.AddCommand(newTxCmd())
 
 	return cmd
 }
 
-func decodePrivateKey(enc string) (*secp256k1.PrivateKey, error) {
-	rawPk := strings.Replace(enc, "PrivateKey-", "", 1)
-	skBytes, err := cb58.Decode(rawPk)
+// DecodePrivateKey accepts PrivateKey-<cb58> keys as well as hex (ethereum style) keys
+func DecodePrivateKey(enc string) (*secp256k1.PrivateKey, error) {
+	skBytes, err := hex.DecodeString(strings.TrimPrefix(enc, "0x"))
+	if err != nil {
+		skBytes, err = cb58.Decode(strings.Replace(enc, "PrivateKey-", "", 1))
+	}
 	if err != nil {
 		return nil, fmt.Errorf("unable to decode private key: %w", err)
 	}
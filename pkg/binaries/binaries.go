@@ -0,0 +1,386 @@
+// Package binaries installs released node and VM binaries into a cache dir
+// shared by every project, e.g. ~/.cache/ggt/bin/subnetevm/v0.4.8/subnet-evm
+package binaries
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-getter"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+)
+
+const (
+	AvalancheGo = "avalanchego"
+	SubnetEVM   = "subnetevm"
+
+	currentFilename = "current"
+	installFilename = "install.json"
+	// sha256s recorded by the first install of each version, in <cache>/<name>
+	knownFilename = "known.json"
+)
+
+// Binary is something `ggt bin` knows how to install
+type Binary struct {
+	// Executable name in the cache
+	Exe string
+	// Names the executable may have inside a release archive
+	ArchiveExes []string
+}
+
+var Binaries = map[string]Binary{
+	AvalancheGo: {Exe: "dijetsnode", ArchiveExes: []string{"dijetsnode", "avalanchego"}},
+	SubnetEVM:   {Exe: "subnet-evm", ArchiveExes: []string{"subnet-evm"}},
+}
+
+// Source is where to download a release from. Both are templates where
+// {version} is the version as given (v1.9.7), {v} is without the leading v,
+// and {os} and {arch} are GOOS and GOARCH. http(s):// and file:// URLs work.
+type Source struct {
+	URL string `json:"url" mapstructure:"url"`
+	// A sha256sum style file listing the archive, empty if the release has none
+	Checksums string `json:"checksums,omitempty" mapstructure:"checksums"`
+	// Don't check releases without checksums against the sha256 recorded when
+	// they were first installed, e.g. after a release was republished
+	Insecure bool `json:"insecure,omitempty" mapstructure:"insecure"`
+}
+
+// DefaultSources are the GitHub releases for the current OS
+func DefaultSources() map[string]Source {
+	avago := Source{URL: "https://github.com/lasthyphen/dijetsnode/releases/download/{version}/dijetsnode-{os}-{arch}-{version}.tar.gz"}
+	if runtime.GOOS == "darwin" {
+		avago.URL = "https://github.com/lasthyphen/dijetsnode/releases/download/{version}/dijetsnode-macos-{version}.zip"
+	}
+	return map[string]Source{
+		AvalancheGo: avago,
+		SubnetEVM: {
+			URL:       "https://github.com/lasthyphen/subnet-evm/releases/download/{version}/subnet-evm_{v}_{os}_{arch}.tar.gz",
+			Checksums: "https://github.com/lasthyphen/subnet-evm/releases/download/{version}/subnet-evm_{v}_checksums.txt",
+		},
+	}
+}
+
+// Expand fills in the placeholders of a Source template
+func Expand(template string, version string) string {
+	return strings.NewReplacer(
+		"{version}", version,
+		"{v}", strings.TrimPrefix(version, "v"),
+		"{os}", runtime.GOOS,
+		"{arch}", runtime.GOARCH,
+	).Replace(template)
+}
+
+// Installed is a version in the cache
+type Installed struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Path    string `json:"path"`
+	Current bool   `json:"current"`
+	InstallInfo
+}
+
+// InstallInfo is recorded next to each installed binary
+type InstallInfo struct {
+	URL string `json:"url"`
+	// Checksums file the archive was verified against, empty if it wasn't
+	Checksums string `json:"checksums,omitempty"`
+	// How the binary was verified, one of the Verified constants, empty if it wasn't
+	Verified    string    `json:"verified,omitempty"`
+	SHA256      string    `json:"sha256"`
+	InstalledAt time.Time `json:"installedAt"`
+}
+
+const (
+	VerifiedChecksums = "checksums"
+	// Against the sha256 recorded by the first install of the version
+	VerifiedRecorded = "recorded"
+	// Not verified, the release has no checksums and this is its first install.
+	// Its sha256 is recorded to verify later installs against.
+	VerifiedFirstUse = "first-use"
+)
+
+var ErrNotInstalled = errors.New("not installed")
+
+type Cache struct {
+	Dir string
+}
+
+// DefaultDir is ggt/bin in the user's cache dir (~/.cache on linux)
+func DefaultDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "ggt", "bin")
+}
+
+func NewCache(dir string) *Cache {
+	if dir == "" {
+		dir = DefaultDir()
+	}
+	return &Cache{Dir: dir}
+}
+
+// Path is where version of name is (or would be) installed
+func (c *Cache) Path(name string, version string) (string, error) {
+	b, ok := Binaries[name]
+	if !ok {
+		return "", fmt.Errorf("unknown binary %s, expected one of %s", name, strings.Join(Names(), ", "))
+	}
+	if version == "" || strings.ContainsAny(version, `/\`) || version == currentFilename {
+		return "", fmt.Errorf("invalid version %q", version)
+	}
+	return filepath.Join(c.Dir, name, version, b.Exe), nil
+}
+
+// Install downloads version of name from src, verifying it against the
+// release checksums. Releases without checksums are trusted on first use: the
+// sha256 of their binary is recorded and later installs of the version must
+// match it, unless the source is insecure. It replaces an existing install
+// only if force is set.
+func (c *Cache) Install(name string, version string, src Source, force bool) (*Installed, error) {
+	dest, err := c.Path(name, version)
+	if err != nil {
+		return nil, err
+	}
+	if utils.FileExists(dest) && !force {
+		return nil, fmt.Errorf("%s %s is already installed at %s", name, version, dest)
+	}
+	if src.URL == "" {
+		return nil, fmt.Errorf("no release source for %s", name)
+	}
+
+	info := InstallInfo{URL: Expand(src.URL, version), InstalledAt: time.Now().UTC()}
+	getURL := info.URL
+	if src.Checksums != "" {
+		info.Checksums = Expand(src.Checksums, version)
+		info.Verified = VerifiedChecksums
+		u, err := url.Parse(info.URL)
+		if err != nil {
+			return nil, err
+		}
+		q := u.Query()
+		q.Set("checksum", "file:"+info.Checksums)
+		u.RawQuery = q.Encode()
+		getURL = u.String()
+	}
+
+	tdir, err := os.MkdirTemp("", "ggt")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tdir)
+	if err := getter.GetAny(tdir, getURL); err != nil {
+		return nil, fmt.Errorf("error downloading %s: %w", info.URL, err)
+	}
+	exe, err := findExe(tdir, Binaries[name].ArchiveExes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", info.URL, err)
+	}
+	if info.SHA256, err = sha256File(exe); err != nil {
+		return nil, err
+	}
+	if info.Checksums == "" {
+		if info.Verified, err = c.verifyKnown(name, version, info.SHA256, src.Insecure); err != nil {
+			return nil, fmt.Errorf("%s: %w", info.URL, err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return nil, err
+	}
+	if err := utils.CopyFile(exe, dest); err != nil {
+		return nil, err
+	}
+	b, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(filepath.Dir(dest), installFilename), b, 0644); err != nil {
+		return nil, err
+	}
+	return c.get(name, version)
+}
+
+// verifyKnown checks sum against the one recorded by the first install of
+// version, recording it if there is none, and returns how sum was verified
+func (c *Cache) verifyKnown(name string, version string, sum string, insecure bool) (string, error) {
+	fn := filepath.Join(c.Dir, name, knownFilename)
+	known := map[string]string{}
+	if b, err := os.ReadFile(fn); err == nil {
+		if err := json.Unmarshal(b, &known); err != nil {
+			return "", fmt.Errorf("invalid %s: %w", fn, err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+
+	switch {
+	case known[version] == sum:
+		return VerifiedRecorded, nil
+	case known[version] != "" && !insecure:
+		return "", fmt.Errorf("sha256 %s doesn't match %s recorded in %s when %s %s was first installed", sum, known[version], fn, name, version)
+	case known[version] != "":
+		return "", nil
+	}
+
+	known[version] = sum
+	b, err := json.MarshalIndent(known, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+		return "", err
+	}
+	return VerifiedFirstUse, os.WriteFile(fn, b, 0644)
+}
+
+// Get returns an installed version, or ErrNotInstalled
+func (c *Cache) Get(name string, version string) (*Installed, error) {
+	if version == "" {
+		current, err := c.Current(name)
+		if err != nil {
+			return nil, err
+		}
+		version = current
+	}
+	return c.get(name, version)
+}
+
+func (c *Cache) get(name string, version string) (*Installed, error) {
+	path, err := c.Path(name, version)
+	if err != nil {
+		return nil, err
+	}
+	if !utils.FileExists(path) {
+		return nil, fmt.Errorf("%s %s: %w", name, version, ErrNotInstalled)
+	}
+	current, _ := c.Current(name)
+	in := &Installed{Name: name, Version: version, Path: path, Current: current == version}
+	if b, err := os.ReadFile(filepath.Join(filepath.Dir(path), installFilename)); err == nil {
+		_ = json.Unmarshal(b, &in.InstallInfo)
+	}
+	return in, nil
+}
+
+// List returns the installed versions of all binaries, by name then version
+func (c *Cache) List() ([]Installed, error) {
+	out := []Installed{}
+	for _, name := range Names() {
+		entries, err := os.ReadDir(filepath.Join(c.Dir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if !e.IsDir() {
+				continue
+			}
+			in, err := c.get(name, e.Name())
+			if errors.Is(err, ErrNotInstalled) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, *in)
+		}
+	}
+	return out, nil
+}
+
+// Remove deletes an installed version, and unsets it if it was the current one
+func (c *Cache) Remove(name string, version string) error {
+	in, err := c.get(name, version)
+	if err != nil {
+		return err
+	}
+	if in.Current {
+		if err := os.Remove(filepath.Join(c.Dir, name, currentFilename)); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(filepath.Dir(in.Path))
+}
+
+// Use makes version the one Get returns when no version is asked for
+func (c *Cache) Use(name string, version string) error {
+	if _, err := c.get(name, version); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(c.Dir, name, currentFilename), []byte(version+"\n"), 0644)
+}
+
+// Current is the version set by Use
+func (c *Cache) Current(name string) (string, error) {
+	if _, ok := Binaries[name]; !ok {
+		return "", fmt.Errorf("unknown binary %s, expected one of %s", name, strings.Join(Names(), ", "))
+	}
+	b, err := os.ReadFile(filepath.Join(c.Dir, name, currentFilename))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("no current version of %s, run 'ggt bin use %s <version>': %w", name, name, ErrNotInstalled)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+func Names() []string {
+	names := make([]string, 0, len(Binaries))
+	for name := range Binaries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// findExe finds the first file in dir with one of names, archives differ in their layout
+func findExe(dir string, names []string) (string, error) {
+	found := map[string]string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if _, ok := found[d.Name()]; !ok && !d.IsDir() {
+			found[d.Name()] = path
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, name := range names {
+		if path, ok := found[name]; ok {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no %s in the release archive", strings.Join(names, " or "))
+}
+
+func sha256File(fn string) (string, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
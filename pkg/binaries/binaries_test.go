@@ -0,0 +1,118 @@
+package binaries
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mirror writes a release archive and checksums file like the ones on GitHub
+func mirror(t *testing.T, version string, exe string) Source {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, fmt.Sprintf("subnet-evm_%s.tar.gz", version[1:]))
+	f, err := os.Create(archive)
+	require.NoError(t, err)
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	content := []byte("#!/bin/sh\necho " + version + "\n")
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: exe, Mode: 0755, Size: int64(len(content))}))
+	_, err = tw.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	require.NoError(t, f.Close())
+
+	b, err := os.ReadFile(archive)
+	require.NoError(t, err)
+	sum := sha256.Sum256(b)
+	checksums := fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), filepath.Base(archive))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "checksums.txt"), []byte(checksums), 0644))
+
+	return Source{
+		URL:       "file://" + dir + "/subnet-evm_{v}.tar.gz",
+		Checksums: "file://" + dir + "/checksums.txt",
+	}
+}
+
+func TestInstallUseRemove(t *testing.T) {
+	c := NewCache(t.TempDir())
+	src := mirror(t, "v0.4.8", "subnet-evm")
+
+	in, err := c.Install(SubnetEVM, "v0.4.8", src, false)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(c.Dir, SubnetEVM, "v0.4.8", "subnet-evm"), in.Path)
+	require.NotEmpty(t, in.SHA256)
+	require.NotEmpty(t, in.Checksums)
+
+	_, err = c.Install(SubnetEVM, "v0.4.8", src, false)
+	require.ErrorContains(t, err, "already installed")
+
+	_, err = c.Get(SubnetEVM, "")
+	require.True(t, errors.Is(err, ErrNotInstalled))
+	require.NoError(t, c.Use(SubnetEVM, "v0.4.8"))
+	in, err = c.Get(SubnetEVM, "")
+	require.NoError(t, err)
+	require.True(t, in.Current)
+
+	list, err := c.List()
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	require.NoError(t, c.Remove(SubnetEVM, "v0.4.8"))
+	list, err = c.List()
+	require.NoError(t, err)
+	require.Empty(t, list)
+	_, err = c.Current(SubnetEVM)
+	require.True(t, errors.Is(err, ErrNotInstalled))
+}
+
+func TestInstallBadChecksum(t *testing.T) {
+	c := NewCache(t.TempDir())
+	src := mirror(t, "v0.4.8", "subnet-evm")
+	checksums := src.Checksums[len("file://"):]
+	require.NoError(t, os.WriteFile(checksums, []byte(fmt.Sprintf("%064d  subnet-evm_0.4.8.tar.gz\n", 0)), 0644))
+
+	_, err := c.Install(SubnetEVM, "v0.4.8", src, false)
+	require.ErrorContains(t, err, "Checksums did not match")
+	_, err = c.Get(SubnetEVM, "v0.4.8")
+	require.True(t, errors.Is(err, ErrNotInstalled))
+}
+
+func TestInstallMissingExe(t *testing.T) {
+	c := NewCache(t.TempDir())
+	_, err := c.Install(SubnetEVM, "v0.4.8", mirror(t, "v0.4.8", "something-else"), false)
+	require.ErrorContains(t, err, "no subnet-evm in the release archive")
+}
+
+func TestInstallTrustOnFirstUse(t *testing.T) {
+	c := NewCache(t.TempDir())
+	src := mirror(t, "v0.4.8", "subnet-evm")
+	src.Checksums = ""
+
+	in, err := c.Install(SubnetEVM, "v0.4.8", src, false)
+	require.NoError(t, err)
+	require.Equal(t, VerifiedFirstUse, in.Verified)
+	in, err = c.Install(SubnetEVM, "v0.4.8", src, true)
+	require.NoError(t, err)
+	require.Equal(t, VerifiedRecorded, in.Verified)
+
+	// A different binary for the same version is refused, unless insecure
+	other := mirror(t, "v0.4.9", "subnet-evm")
+	src = Source{URL: strings.Replace(other.URL, "{v}", "0.4.9", 1)}
+	_, err = c.Install(SubnetEVM, "v0.4.8", src, true)
+	require.ErrorContains(t, err, "doesn't match")
+
+	src.Insecure = true
+	in, err = c.Install(SubnetEVM, "v0.4.8", src, true)
+	require.NoError(t, err)
+	require.Empty(t, in.Verified)
+}
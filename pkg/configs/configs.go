@@ -2,11 +2,6 @@ package configs
 
 import (
 	_ "embed"
-	"encoding/json"
-	"errors"
-	"fmt"
-	"log"
-	"time"
 )
 
 const (
@@ -19,6 +14,7 @@ const (
 	BashScriptFilename   = "start.sh"
 	AccountsFilename     = "accounts.json"
 	ContractsFilename    = "contracts.json"
+	NetworkFilename      = "network.json"
 )
 
 //go:embed accounts.json
@@ -48,57 +44,7 @@ var Readme string
 //go:embed start.sh
 var StartBash string
 
+// The start time and locktimes are from 2021, see genesis.RetimePrimary
+//
 //go:embed ava-genesis.json
-var AvaGenesisRaw []byte
-
-// We need to munge the timestamps from the raw file
-var AvaGenesis string
-
-func init() {
-	// difference between unlock schedule locktime and startime in original genesis
-	const genesisLocktimeStartimeDelta = 2836800
-
-	var (
-		genesisMap map[string]interface{}
-		err        error
-	)
-
-	if err = json.Unmarshal(AvaGenesisRaw, &genesisMap); err != nil {
-		log.Fatalf("error reading ava-genesis.json %s", err)
-	}
-
-	startTime := time.Now().Unix()
-	lockTime := startTime + genesisLocktimeStartimeDelta
-	genesisMap["startTime"] = float64(startTime)
-	allocations, ok := genesisMap["allocations"].([]interface{})
-	if !ok {
-		panic(errors.New("could not get allocations in genesis"))
-	}
-	for _, allocIntf := range allocations {
-		alloc, ok := allocIntf.(map[string]interface{})
-		if !ok {
-			panic(fmt.Errorf("unexpected type for allocation in genesis. got %T", allocIntf))
-		}
-		unlockSchedule, ok := alloc["unlockSchedule"].([]interface{})
-		if !ok {
-			panic(errors.New("could not get unlockSchedule in allocation"))
-		}
-		for _, schedIntf := range unlockSchedule {
-			sched, ok := schedIntf.(map[string]interface{})
-			if !ok {
-				panic(fmt.Errorf("unexpected type for unlockSchedule elem in genesis. got %T", schedIntf))
-			}
-			if _, ok := sched["locktime"]; ok {
-				sched["locktime"] = float64(lockTime)
-			}
-		}
-	}
-
-	// now we can marshal the *whole* thing into bytes
-	updatedGenesis, err := json.Marshal(genesisMap)
-	if err != nil {
-		panic(err)
-	}
-
-	AvaGenesis = string(updatedGenesis)
-}
+var AvaGenesis []byte
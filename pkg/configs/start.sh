@@ -3,20 +3,25 @@ pushd "${BASH_SOURCE%/*}"
 
 # This is the command that "ggt run <node>" uses
 #
-# Disables staking
+# Disables staking (unless the node was created by "ggt network create",
+# which sets up staking certs and bootstrap peers in node-config.json)
 # Validates all subnets that have plugins installed
 # Allow connections from anywhere 
 # Disable NAT 
 # Dont try to connect to anyone bootstrap nodes
+# Dont report unhealthy for having no peers, when there is no one to connect to
 
 cmd="bin/dijetsnode \
 	--http-host=0.0.0.0 \
 	--public-ip=127.0.0.1 \
+{{- if not .Staking}}
 	--bootstrap-ids= \
 	--bootstrap-ips= \
 	--staking-enabled=false \
 	--staking-ephemeral-cert-enabled=true \
   --staking-ephemeral-signer-enabled=true \
+	--network-health-min-conn-peers=0 \
+{{- end}}
   --index-enabled=true \
   --api-keystore-enabled=true \
   --api-admin-enabled=true \
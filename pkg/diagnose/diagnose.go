@@ -0,0 +1,293 @@
+// Package diagnose explains why a node exited or a chain didn't come up, by
+// matching known failure patterns in the node's main.log and chain logs.
+package diagnose
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/tidwall/gjson"
+)
+
+// How much of the end of each log is read
+const tailBytes = 256 * 1024
+
+// Lines kept per finding, and shown when nothing matched
+const maxLines = 3
+
+type Finding struct {
+	Problem string `json:"problem"`
+	// What to do about it
+	Hint  string   `json:"hint"`
+	File  string   `json:"file"`
+	Lines []string `json:"lines"`
+}
+
+type pattern struct {
+	re *regexp.Regexp
+	// explain gets the submatches of the first matching line
+	explain func(workDir string, m []string) (problem string, hint string)
+}
+
+func fixed(problem string, hint string) func(string, []string) (string, string) {
+	return func(string, []string) (string, string) { return problem, hint }
+}
+
+// Only lines that look like they report a problem are matched
+var problemRe = regexp.MustCompile(`(?i)error|fatal|fail|warn|crit|panic`)
+
+var patterns = []pattern{
+	{
+		regexp.MustCompile(`error while getting vmFactory: \\?"(\w+)\\?" was not found`),
+		explainMissingVM,
+	},
+	{
+		regexp.MustCompile(`invalid vmID: \\?"([^"\\]+)\\?"`),
+		func(_ string, m []string) (string, string) {
+			return "plugin not named after a VM ID",
+				fmt.Sprintf("bin/plugins/%s must be named after the VM's ID, relink it with 'ggt node vm add'", m[1])
+		},
+	},
+	{
+		regexp.MustCompile(`(?i)incompatible API version with plugin|rpcchainvm.*protocol.*(mismatch|incompatible)`),
+		fixed("rpcchainvm protocol mismatch",
+			"the plugin was built for a different dijetsnode version, run 'ggt node check' and use a matching plugin"),
+	},
+	{
+		regexp.MustCompile(`(?i)plugin exited before we could connect|exec format error`),
+		fixed("plugin failed to start",
+			"the plugin crashed or isn't built for this platform, try running it directly from bin/plugins"),
+	},
+	{
+		regexp.MustCompile(`(?i)genesis has no chain configuration|(unmarshal|parse|invalid|decode|build)\w*[^"]{0,40}genesis|genesis[^"]{0,40}(unmarshal|parse|invalid|mismatch)`),
+		fixed("bad genesis",
+			"the genesis file doesn't match what the VM expects, check it against the VM's version"),
+	},
+	{
+		regexp.MustCompile(`(?i)address already in use`),
+		func(workDir string, _ []string) (string, string) {
+			httpPort, stakingPort := utils.NodePorts(workDir)
+			return "port in use", fmt.Sprintf("another process (maybe another node, see 'ggt node list') is using http-port %d or staking-port %d, change them in %s",
+				httpPort, stakingPort, utils.NewFileLocations(workDir).ConfigFile)
+		},
+	},
+	{
+		regexp.MustCompile(`(?i)LOCK: resource temporarily unavailable|database.{0,40}locked|lock.{0,20}held by`),
+		fixed("database locked",
+			"another dijetsnode is using this data dir, stop it (see 'ggt node status') before starting this one"),
+	},
+}
+
+// explainMissingVM tells a VM with no plugin apart from a plugin linked under the wrong VM ID
+func explainMissingVM(workDir string, m []string) (string, string) {
+	vmID := m[1]
+	entries, _ := os.ReadDir(utils.NewDirectoryLayout(workDir).PluginDir)
+	if len(entries) == 0 {
+		return "missing plugin", fmt.Sprintf("the chain needs VM %s but bin/plugins is empty, add it with 'ggt node vm add'", vmID)
+	}
+	names := []string{}
+	aliases, _ := os.ReadFile(utils.NewFileLocations(workDir).VMAliasesFile)
+	for _, e := range entries {
+		name := e.Name()
+		if alias := gjson.GetBytes(aliases, name+".0").String(); alias != "" {
+			name = fmt.Sprintf("%s (%s)", name, alias)
+		}
+		names = append(names, name)
+	}
+	return "VM ID mismatch", fmt.Sprintf("the chain needs VM %s but bin/plugins only has %s, was the chain created with a different VM name? ('ggt utils vmid' shows the ID for a name)",
+		vmID, strings.Join(names, ", "))
+}
+
+// Node diagnoses the node in workDir from the last run in main.log and the
+// chain logs written since since (all of them if it is zero)
+func Node(workDir string, since time.Time) ([]Finding, error) {
+	logsDir := logsDir(workDir)
+	files := []string{filepath.Join(logsDir, "main.log")}
+	entries, err := os.ReadDir(logsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, e := range entries {
+		fn := filepath.Join(logsDir, e.Name())
+		info, err := e.Info()
+		if err != nil || e.Name() == "main.log" || !strings.HasSuffix(e.Name(), ".log") || info.ModTime().Before(since) {
+			continue
+		}
+		files = append(files, fn)
+	}
+	return scanFiles(workDir, files, "")
+}
+
+// Chain diagnoses one chain, given by ID or alias, from its own logs (named
+// after its ID or aliases) and the lines of main.log that mention its ID
+func Chain(workDir string, chain string) ([]Finding, error) {
+	chainID := chain
+	names := []string{}
+	if b, err := os.ReadFile(utils.NewFileLocations(workDir).ChainAliasesFile); err == nil {
+		// The node logs chain IDs, so an alias is looked up first
+		gjson.ParseBytes(b).ForEach(func(id, aliases gjson.Result) bool {
+			found := id.String() == chain
+			for _, a := range aliases.Array() {
+				found = found || a.String() == chain
+			}
+			if !found {
+				return true
+			}
+			chainID = id.String()
+			for _, a := range aliases.Array() {
+				names = append(names, a.String())
+			}
+			return false
+		})
+	}
+	names = append([]string{chainID}, names...)
+
+	files := []string{filepath.Join(logsDir(workDir), "main.log")}
+	for _, name := range names {
+		files = append(files, filepath.Join(logsDir(workDir), name+".log"))
+	}
+	return scanFiles(workDir, files, chainID)
+}
+
+// Tail is the last n lines of the last run in main.log, for when nothing matched
+func Tail(workDir string, n int) []string {
+	lines, _ := lastRun(filepath.Join(logsDir(workDir), "main.log"))
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
+
+func logsDir(workDir string) string {
+	return filepath.Join(utils.NewDirectoryLayout(workDir).DataDir, "logs")
+}
+
+func scanFiles(workDir string, files []string, mention string) ([]Finding, error) {
+	findings := []Finding{}
+	for _, fn := range files {
+		lines, err := lastRun(fn)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if mention != "" && filepath.Base(fn) == "main.log" {
+			lines = filter(lines, mention)
+		}
+		findings = append(findings, Scan(workDir, fn, lines)...)
+	}
+	return findings, nil
+}
+
+// Scan matches the known failure patterns against lines from fn, one finding per problem
+func Scan(workDir string, fn string, lines []string) []Finding {
+	byProblem := map[string]*Finding{}
+	order := []string{}
+	for _, line := range lines {
+		if !problemRe.MatchString(line) {
+			continue
+		}
+		for _, p := range patterns {
+			m := p.re.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			problem, hint := p.explain(workDir, m)
+			f, ok := byProblem[problem]
+			if !ok {
+				f = &Finding{Problem: problem, Hint: hint, File: fn}
+				byProblem[problem] = f
+				order = append(order, problem)
+			}
+			if len(f.Lines) < maxLines {
+				f.Lines = append(f.Lines, strings.TrimSpace(line))
+			}
+			break
+		}
+	}
+	out := []Finding{}
+	for _, problem := range order {
+		out = append(out, *byProblem[problem])
+	}
+	return out
+}
+
+// Format renders findings for the terminal, or the tail of main.log if there are none
+func Format(workDir string, findings []Finding) string {
+	sb := &strings.Builder{}
+	if len(findings) == 0 {
+		fmt.Fprintf(sb, "No known problem found in %s, its last lines are:\n", logsDir(workDir))
+		for _, line := range Tail(workDir, 10) {
+			fmt.Fprintf(sb, "  %s\n", line)
+		}
+		fmt.Fprintf(sb, "Errors before logging starts (like bad flags) only go to stdout, run VERBOSE=1 %s/start.sh to see them\n", workDir)
+		return sb.String()
+	}
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].File < findings[j].File })
+	for _, f := range findings {
+		fmt.Fprintf(sb, "%s: %s\n", f.Problem, f.Hint)
+		for _, line := range f.Lines {
+			fmt.Fprintf(sb, "  %s: %s\n", filepath.Base(f.File), line)
+		}
+	}
+	return sb.String()
+}
+
+// lastRun is the end of fn, starting from the last time the node started if fn is main.log
+func lastRun(fn string) ([]string, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := info.Size() - tailBytes
+	if offset < 0 {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		// Drop the partial first line
+		if i := bytes.IndexByte(b, '\n'); i >= 0 {
+			b = b[i+1:]
+		}
+	}
+
+	lines := strings.Split(strings.TrimRight(string(b), "\n"), "\n")
+	if filepath.Base(fn) == "main.log" {
+		for i := len(lines) - 1; i >= 0; i-- {
+			if strings.Contains(lines[i], "initializing node") {
+				return lines[i:], nil
+			}
+		}
+	}
+	return lines, nil
+}
+
+func filter(lines []string, substr string) []string {
+	out := []string{}
+	for _, line := range lines {
+		if strings.Contains(line, substr) {
+			out = append(out, line)
+		}
+	}
+	return out
+}
@@ -0,0 +1,53 @@
+package diagnose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lasthyphen/ecctools/pkg/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNode(t *testing.T) {
+	workDir := t.TempDir()
+	testutil.WriteFile(t, filepath.Join(workDir, "configs", "node-config.json"), `{"http-port":9660,"staking-port":9661}`)
+	testutil.WriteFile(t, filepath.Join(workDir, "data", "logs", "main.log"), `[01-02|15:04:05.000] INFO node/node.go:1223 initializing node
+[01-02|15:04:06.000] FATAL node/node.go:100 failed to listen {"error": "listen tcp 0.0.0.0:9660: bind: address already in use"}
+[01-02|15:05:00.000] INFO node/node.go:1223 initializing node
+[01-02|15:05:01.000] INFO chains/manager.go:200 creating chain {"chainID": "abc"}
+[01-02|15:05:02.000] ERROR chains/manager.go:331 error creating chain {"chainID": "abc", "error": "error while getting vmFactory: \"srEXiWaHuhNyGwPUi444Tu47ZEDwxTWrbQiuD7FmgSAQ6X7Dy\" was not found"}
+`)
+
+	// Only the last run counts, and an empty plugin dir means a missing plugin
+	findings, err := Node(workDir, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	require.Equal(t, "missing plugin", findings[0].Problem)
+	require.Contains(t, findings[0].Hint, "srEXiWaHuhNyGwPUi444Tu47ZEDwxTWrbQiuD7FmgSAQ6X7Dy")
+	require.Len(t, findings[0].Lines, 1)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(workDir, "bin", "plugins"), 0755))
+	testutil.WriteFile(t, filepath.Join(workDir, "bin", "plugins", "otherVM"), "")
+	findings, err = Chain(workDir, "abc")
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	require.Equal(t, "VM ID mismatch", findings[0].Problem)
+	require.Contains(t, findings[0].Hint, "otherVM")
+
+	// An alias finds the chain's lines in main.log, which only has its ID
+	testutil.WriteFile(t, filepath.Join(workDir, "configs", "chains", "aliases.json"), `{"abc":["mychain"]}`)
+	findings, err = Chain(workDir, "mychain")
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	require.Equal(t, "VM ID mismatch", findings[0].Problem)
+
+	findings = Scan(workDir, "main.log", []string{`FATAL failed to listen {"error": "bind: address already in use"}`})
+	require.Len(t, findings, 1)
+	require.Equal(t, "port in use", findings[0].Problem)
+	require.Contains(t, findings[0].Hint, "9660")
+
+	require.Empty(t, Scan(workDir, "main.log", []string{"INFO parsed genesis"}))
+	require.Contains(t, Format(workDir, nil), "error creating chain")
+}
@@ -0,0 +1,211 @@
+package genesis
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	avagenesis "github.com/lasthyphen/dijetsnode/genesis"
+	"github.com/lasthyphen/dijetsnode/ids"
+	"github.com/lasthyphen/dijetsnode/utils/constants"
+	"github.com/lasthyphen/dijetsnode/utils/formatting/address"
+	"github.com/lasthyphen/ecctools/pkg/configs"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Account is a key that gets funds on the primary network
+type Account struct {
+	Name    string
+	Addr    ids.ShortID
+	EthAddr common.Address
+}
+
+// Amount of nAVAX for an account, locked until Locktime after the start time (0 is unlocked)
+type Amount struct {
+	Account
+	Amount   uint64
+	Locktime time.Duration
+}
+
+type PrimaryOptions struct {
+	NetworkID     uint32
+	StartTime     time.Time
+	StakeDuration time.Duration
+	StakeOffset   time.Duration
+	// X-chain funds, Locktime is ignored
+	XAlloc []Amount
+	// P-chain funds
+	PAlloc []Amount
+	// P-chain funds that are staked by Stakers (and returned to the account after)
+	StakedFunds []Amount
+	// C-chain funds in wei
+	CAlloc        map[common.Address]*big.Int
+	CChainID      uint64
+	Stakers       []ids.NodeID
+	RewardAddr    ids.ShortID
+	DelegationFee uint32
+	Message       string
+}
+
+// DefaultStartTime is the start of the current UTC day, so genesis files made
+// on the same day are identical. It has to be in the past for the node to start.
+func DefaultStartTime() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
+
+// Primary renders a primary network genesis. Allocations are in the order
+// accounts first appear in XAlloc, PAlloc then StakedFunds, so the same
+// options always give the same bytes.
+func Primary(opts PrimaryOptions) ([]byte, error) {
+	hrp := constants.GetHRP(opts.NetworkID)
+	start := uint64(opts.StartTime.Unix())
+	lock := func(a Amount) avagenesis.LockedAmount {
+		l := avagenesis.LockedAmount{Amount: a.Amount}
+		if a.Locktime > 0 {
+			l.Locktime = start + uint64(a.Locktime.Seconds())
+		}
+		return l
+	}
+
+	staked := map[ids.ShortID]bool{}
+	for _, a := range opts.StakedFunds {
+		staked[a.Addr] = true
+	}
+	for _, a := range opts.PAlloc {
+		if staked[a.Addr] {
+			return nil, fmt.Errorf("%s has staked funds, so all of its P-chain funds would be staked too", a.Name)
+		}
+	}
+
+	cfg := avagenesis.UnparsedConfig{
+		NetworkID:                  opts.NetworkID,
+		Allocations:                []avagenesis.UnparsedAllocation{},
+		StartTime:                  start,
+		InitialStakeDuration:       uint64(opts.StakeDuration.Seconds()),
+		InitialStakeDurationOffset: uint64(opts.StakeOffset.Seconds()),
+		InitialStakedFunds:         []string{},
+		InitialStakers:             []avagenesis.UnparsedStaker{},
+		Message:                    opts.Message,
+	}
+
+	allocs := map[ids.ShortID]int{}
+	alloc := func(a Amount) (*avagenesis.UnparsedAllocation, error) {
+		if i, ok := allocs[a.Addr]; ok {
+			return &cfg.Allocations[i], nil
+		}
+		avaxAddr, err := address.Format("X", hrp, a.Addr.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		allocs[a.Addr] = len(cfg.Allocations)
+		cfg.Allocations = append(cfg.Allocations, avagenesis.UnparsedAllocation{
+			ETHAddr:        a.EthAddr.Hex(),
+			AVAXAddr:       avaxAddr,
+			UnlockSchedule: []avagenesis.LockedAmount{},
+		})
+		return &cfg.Allocations[len(cfg.Allocations)-1], nil
+	}
+	for _, a := range opts.XAlloc {
+		ua, err := alloc(a)
+		if err != nil {
+			return nil, err
+		}
+		ua.InitialAmount += a.Amount
+	}
+	for _, a := range append(append([]Amount{}, opts.PAlloc...), opts.StakedFunds...) {
+		ua, err := alloc(a)
+		if err != nil {
+			return nil, err
+		}
+		ua.UnlockSchedule = append(ua.UnlockSchedule, lock(a))
+	}
+	for _, a := range opts.StakedFunds {
+		if avaxAddr := cfg.Allocations[allocs[a.Addr]].AVAXAddr; !contains(cfg.InitialStakedFunds, avaxAddr) {
+			cfg.InitialStakedFunds = append(cfg.InitialStakedFunds, avaxAddr)
+		}
+	}
+
+	rewardAddr, err := address.Format("X", hrp, opts.RewardAddr.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	for _, nodeID := range opts.Stakers {
+		cfg.InitialStakers = append(cfg.InitialStakers, avagenesis.UnparsedStaker{
+			NodeID:        nodeID,
+			RewardAddress: rewardAddr,
+			DelegationFee: opts.DelegationFee,
+		})
+	}
+
+	if cfg.CChainGenesis, err = cChainGenesis(opts.CChainID, opts.CAlloc); err != nil {
+		return nil, err
+	}
+
+	b, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidatePrimary(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// cChainGenesis is the C-chain genesis of the embedded ava-genesis.json with a
+// new chain ID and alloc
+func cChainGenesis(chainID uint64, alloc map[common.Address]*big.Int) (string, error) {
+	out := gjson.GetBytes(configs.AvaGenesis, "cChainGenesis").String()
+	out, err := sjson.Set(out, "config.chainId", chainID)
+	if err != nil {
+		return "", err
+	}
+	// Maps are marshalled with sorted keys
+	balances := map[string]map[string]string{}
+	for addr, wei := range alloc {
+		balances[addr.Hex()] = map[string]string{"balance": hexutil.EncodeBig(wei)}
+	}
+	return sjson.Set(out, "alloc", balances)
+}
+
+// ValidatePrimary runs the checks the node does on a custom genesis when it starts
+func ValidatePrimary(b []byte) error {
+	networkID := gjson.GetBytes(b, "networkID").Uint()
+	// FromFlag takes the genesis the way --genesis-content does, base64 encoded
+	if _, _, err := avagenesis.FromFlag(uint32(networkID), base64.StdEncoding.EncodeToString(b)); err != nil {
+		return fmt.Errorf("invalid genesis: %w", err)
+	}
+	return nil
+}
+
+// RetimePrimary moves the start time of a primary genesis, shifting its
+// locktimes by the same amount.
+func RetimePrimary(b []byte, startTime time.Time) ([]byte, error) {
+	cfg := avagenesis.UnparsedConfig{}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return nil, err
+	}
+	shift := startTime.Unix() - int64(cfg.StartTime)
+	for i := range cfg.Allocations {
+		for j := range cfg.Allocations[i].UnlockSchedule {
+			if l := &cfg.Allocations[i].UnlockSchedule[j]; l.Locktime != 0 {
+				l.Locktime = uint64(int64(l.Locktime) + shift)
+			}
+		}
+	}
+	cfg.StartTime = uint64(startTime.Unix())
+	return json.MarshalIndent(cfg, "", "  ")
+}
+
+func contains(s []string, v string) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
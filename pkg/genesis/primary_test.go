@@ -0,0 +1,60 @@
+package genesis
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lasthyphen/dijetsnode/ids"
+	"github.com/lasthyphen/ecctools/pkg/configs"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func Test_Primary(t *testing.T) {
+	owner := Account{Name: "owner", Addr: ids.ShortID{1}, EthAddr: common.Address{1}}
+	staker := Account{Name: "staker", Addr: ids.ShortID{2}, EthAddr: common.Address{2}}
+	nodeID, err := ids.NodeIDFromString("NodeID-7Xhw2mDxuDS44j42TCB6U5579esbSt3Lg")
+	require.NoError(t, err)
+
+	opts := PrimaryOptions{
+		NetworkID:     4242,
+		StartTime:     time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+		StakeDuration: 24 * time.Hour,
+		StakeOffset:   time.Hour,
+		XAlloc:        []Amount{{Account: owner, Amount: 1000}},
+		PAlloc:        []Amount{{Account: owner, Amount: 2000}, {Account: owner, Amount: 3000, Locktime: time.Hour}},
+		StakedFunds:   []Amount{{Account: staker, Amount: 5000}},
+		CAlloc:        map[common.Address]*big.Int{owner.EthAddr: big.NewInt(1e18)},
+		CChainID:      4321,
+		Stakers:       []ids.NodeID{nodeID},
+		RewardAddr:    owner.Addr,
+	}
+	b1, err := Primary(opts)
+	require.NoError(t, err)
+	b2, err := Primary(opts)
+	require.NoError(t, err)
+	require.Equal(t, b1, b2)
+
+	g := gjson.ParseBytes(b1)
+	require.Equal(t, int64(1685577600), g.Get("startTime").Int())
+	require.Equal(t, int64(1000), g.Get("allocations.0.initialAmount").Int())
+	require.Equal(t, int64(1685577600+3600), g.Get("allocations.0.unlockSchedule.1.locktime").Int())
+	require.Equal(t, g.Get("allocations.1.avaxAddr").String(), g.Get("initialStakedFunds.0").String())
+	require.Equal(t, int64(4321), gjson.Parse(g.Get("cChainGenesis").String()).Get("config.chainId").Int())
+
+	opts.PAlloc = append(opts.PAlloc, Amount{Account: staker, Amount: 1})
+	_, err = Primary(opts)
+	require.ErrorContains(t, err, "staked")
+}
+
+func Test_RetimePrimary(t *testing.T) {
+	start := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	b, err := RetimePrimary(configs.AvaGenesis, start)
+	require.NoError(t, err)
+	require.NoError(t, ValidatePrimary(b))
+	g := gjson.ParseBytes(b)
+	require.Equal(t, start.Unix(), g.Get("startTime").Int())
+	require.Equal(t, start.Unix()+2836800, g.Get("allocations.0.unlockSchedule.0.locktime").Int())
+}
@@ -0,0 +1,172 @@
+// Package genesis builds and validates genesis files for the chains ggt runs.
+package genesis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/lasthyphen/ecctools/pkg/configs"
+	"github.com/lasthyphen/utilitychain/core"
+	"github.com/tidwall/sjson"
+)
+
+type FeeConfig struct {
+	GasLimit                 uint64 `json:"gasLimit"`
+	TargetBlockRate          uint64 `json:"targetBlockRate"`
+	MinBaseFee               uint64 `json:"minBaseFee"`
+	TargetGas                uint64 `json:"targetGas"`
+	BaseFeeChangeDenominator uint64 `json:"baseFeeChangeDenominator"`
+	MinBlockGasCost          uint64 `json:"minBlockGasCost"`
+	MaxBlockGasCost          uint64 `json:"maxBlockGasCost"`
+	BlockGasCostStep         uint64 `json:"blockGasCostStep"`
+}
+
+// DefaultFeeConfig is the feeConfig of the embedded subnetevm-genesis.json
+var DefaultFeeConfig = FeeConfig{
+	GasLimit:                 8_000_000,
+	TargetBlockRate:          2,
+	MinBaseFee:               25_000_000_000,
+	TargetGas:                50_000_000,
+	BaseFeeChangeDenominator: 36,
+	MinBlockGasCost:          0,
+	MaxBlockGasCost:          1_000_000,
+	BlockGasCostStep:         200_000,
+}
+
+func (f FeeConfig) Verify() error {
+	switch {
+	case f.GasLimit == 0:
+		return errors.New("feeConfig.gasLimit must be > 0")
+	case f.TargetBlockRate == 0:
+		return errors.New("feeConfig.targetBlockRate must be > 0")
+	case f.MinBaseFee == 0:
+		return errors.New("feeConfig.minBaseFee must be > 0")
+	case f.TargetGas == 0:
+		return errors.New("feeConfig.targetGas must be > 0")
+	case f.BaseFeeChangeDenominator == 0:
+		return errors.New("feeConfig.baseFeeChangeDenominator must be > 0")
+	case f.MinBlockGasCost > f.MaxBlockGasCost:
+		return fmt.Errorf("feeConfig.minBlockGasCost (%d) is more than maxBlockGasCost (%d)", f.MinBlockGasCost, f.MaxBlockGasCost)
+	}
+	return nil
+}
+
+// Precompile config keys, in the order they appear in the genesis
+const (
+	DeployerAllowList = "contractDeployerAllowListConfig"
+	NativeMinter      = "contractNativeMinterConfig"
+	TxAllowList       = "txAllowListConfig"
+	FeeManager        = "feeManagerConfig"
+	RewardManager     = "rewardManagerConfig"
+)
+
+var Precompiles = []string{DeployerAllowList, NativeMinter, TxAllowList, FeeManager, RewardManager}
+
+type PrecompileConfig struct {
+	AdminAddresses   []common.Address `json:"adminAddresses"`
+	EnabledAddresses []common.Address `json:"enabledAddresses"`
+	BlockTimestamp   *uint64          `json:"blockTimestamp"`
+}
+
+type SubnetEVMOptions struct {
+	ChainID            uint64
+	FeeConfig          FeeConfig
+	AllowFeeRecipients bool
+	// precompile key => admin addresses, precompiles not in here are disabled
+	Precompiles map[string][]common.Address
+	// address => balance in wei
+	Alloc map[common.Address]*big.Int
+}
+
+// SubnetEVM renders a subnet-evm genesis from the embedded template and validates it
+func SubnetEVM(opts SubnetEVMOptions) ([]byte, error) {
+	out := configs.SubnetEVMGenesis
+	var err error
+	set := func(path string, value interface{}) {
+		if err == nil {
+			out, err = sjson.Set(out, path, value)
+		}
+	}
+
+	set("config.chainId", opts.ChainID)
+	set("config.allowFeeRecipients", opts.AllowFeeRecipients)
+	set("config.feeConfig", opts.FeeConfig)
+	set("gasLimit", hexutil.EncodeUint64(opts.FeeConfig.GasLimit))
+	for _, p := range Precompiles {
+		if err == nil {
+			out, err = sjson.Delete(out, "config."+p)
+		}
+		if admins, ok := opts.Precompiles[p]; ok {
+			ts := uint64(0)
+			set("config."+p, PrecompileConfig{AdminAddresses: admins, BlockTimestamp: &ts})
+		}
+	}
+
+	alloc := map[string]map[string]string{}
+	for addr, balance := range opts.Alloc {
+		alloc[addr.Hex()] = map[string]string{"balance": hexutil.EncodeBig(balance)}
+	}
+	set("alloc", alloc)
+	if err != nil {
+		return nil, err
+	}
+
+	b := []byte(out)
+	if err := ValidateSubnetEVM(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// ValidateSubnetEVM checks a subnet-evm genesis parses and is self-consistent,
+// which would otherwise only show up when the chain fails to start.
+func ValidateSubnetEVM(b []byte) error {
+	g := core.Genesis{}
+	if err := json.Unmarshal(b, &g); err != nil {
+		return fmt.Errorf("invalid genesis: %w", err)
+	}
+	if g.Config == nil || g.Config.ChainID == nil || g.Config.ChainID.Sign() <= 0 {
+		return errors.New("invalid genesis: config.chainId must be > 0")
+	}
+
+	// params.ChainConfig doesn't know about the subnet-evm additions
+	raw := struct {
+		Config map[string]json.RawMessage `json:"config"`
+	}{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return fmt.Errorf("invalid genesis: %w", err)
+	}
+	feeConfig := FeeConfig{}
+	f, ok := raw.Config["feeConfig"]
+	if !ok {
+		return errors.New("invalid genesis: config.feeConfig is missing")
+	}
+	if err := json.Unmarshal(f, &feeConfig); err != nil {
+		return fmt.Errorf("invalid genesis: feeConfig: %w", err)
+	}
+	if err := feeConfig.Verify(); err != nil {
+		return fmt.Errorf("invalid genesis: %w", err)
+	}
+	if g.GasLimit != feeConfig.GasLimit {
+		return fmt.Errorf("invalid genesis: gasLimit (%d) must equal feeConfig.gasLimit (%d)", g.GasLimit, feeConfig.GasLimit)
+	}
+
+	for _, p := range Precompiles {
+		r, ok := raw.Config[p]
+		if !ok {
+			continue
+		}
+		pc := PrecompileConfig{}
+		if err := json.Unmarshal(r, &pc); err != nil {
+			return fmt.Errorf("invalid genesis: %s: %w", p, err)
+		}
+		if pc.BlockTimestamp == nil {
+			return fmt.Errorf("invalid genesis: %s.blockTimestamp is missing", p)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package genesis
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lasthyphen/ecctools/pkg/configs"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func Test_SubnetEVM(t *testing.T) {
+	require.NoError(t, ValidateSubnetEVM([]byte(configs.SubnetEVMGenesis)))
+
+	owner := common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+	b, err := SubnetEVM(SubnetEVMOptions{
+		ChainID:     1234,
+		FeeConfig:   DefaultFeeConfig,
+		Precompiles: map[string][]common.Address{NativeMinter: {owner}},
+		Alloc:       map[common.Address]*big.Int{owner: big.NewInt(1e18)},
+	})
+	require.NoError(t, err)
+	g := gjson.ParseBytes(b)
+	require.Equal(t, int64(1234), g.Get("config.chainId").Int())
+	require.Equal(t, "0x7a1200", g.Get("gasLimit").String())
+	require.Equal(t, strings.ToLower(owner.Hex()), g.Get("config.contractNativeMinterConfig.adminAddresses.0").String())
+	require.False(t, g.Get("config.txAllowListConfig").Exists())
+	require.Equal(t, "0xde0b6b3a7640000", g.Get("alloc."+owner.Hex()+".balance").String())
+
+	fees := DefaultFeeConfig
+	fees.MinBaseFee = 0
+	_, err = SubnetEVM(SubnetEVMOptions{ChainID: 1234, FeeConfig: fees})
+	require.ErrorContains(t, err, "minBaseFee")
+
+	require.ErrorContains(t, ValidateSubnetEVM([]byte(`{"config":{"chainId":1},"alloc":{"nothex":{"balance":"0x1"}}}`)), "invalid genesis")
+}
@@ -0,0 +1,177 @@
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ManifestFilename = "ggt.yaml"
+	LockFilename     = "ggt.lock.json"
+)
+
+// Manifest describes a whole project, so `ggt up` can (re)create it. Example:
+//
+//	versions:
+//	  avalanchego: v1.9.7
+//	  subnetevm: v0.4.8
+//	nodes:
+//	  - name: NodeV1
+//	    vms:
+//	      subnetevm: subnet-evm-v0.4.8
+//	subnets:
+//	  - name: MySubnet
+//	    node: NodeV1
+//	chains:
+//	  - name: MyChain
+//	    node: NodeV1
+//	    subnet: MySubnet
+//	    vm: subnetevm
+//	    genesis: subnetevm-genesis.json
+//	    config: subnetevm-config.json
+//	    alloc:
+//	      alice: 1000ether
+type Manifest struct {
+	// Binary versions to link into the project dir from the 'ggt bin' cache, which
+	// downloads them if they aren't cached yet
+	Versions map[string]string `yaml:"versions"`
+	Nodes    []Node            `yaml:"nodes"`
+	Subnets  []Subnet          `yaml:"subnets"`
+	Chains   []Chain           `yaml:"chains"`
+}
+
+type Node struct {
+	Name string `yaml:"name"`
+	// Defaults to the downloaded dijetsnode-<versions.avalanchego>
+	AvaBin string `yaml:"avaBin"`
+	// vm name => binary
+	VMs map[string]string `yaml:"vms"`
+}
+
+type Subnet struct {
+	Name string `yaml:"name"`
+	Node string `yaml:"node"`
+}
+
+type Chain struct {
+	Name    string `yaml:"name"`
+	Node    string `yaml:"node"`
+	Subnet  string `yaml:"subnet"`
+	VM      string `yaml:"vm"`
+	Genesis string `yaml:"genesis"`
+	Config  string `yaml:"config"`
+	// accounts.json name (or 0x address) => amount (wei or e.g. 10ether)
+	Alloc map[string]string `yaml:"alloc"`
+}
+
+// Lock records what `ggt up` created, so it is not created twice and teammates
+// can check they ended up with the same IDs.
+type Lock struct {
+	Subnets map[string]LockedSubnet `json:"subnets"`
+	Chains  map[string]LockedChain  `json:"chains"`
+}
+
+type LockedSubnet struct {
+	ID   string `json:"id"`
+	Node string `json:"node"`
+}
+
+type LockedChain struct {
+	ID       string `json:"id"`
+	SubnetID string `json:"subnetID"`
+	VMID     string `json:"vmID"`
+	Node     string `json:"node"`
+}
+
+func Load(path string) (*Manifest, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{}
+	if err := yaml.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+	return m, m.Validate()
+}
+
+// Validate checks that everything referenced by name is defined
+func (m *Manifest) Validate() error {
+	nodes := map[string]Node{}
+	for _, n := range m.Nodes {
+		if n.Name == "" {
+			return fmt.Errorf("node is missing a name")
+		}
+		if n.AvaBin == "" && m.Versions["avalanchego"] == "" {
+			return fmt.Errorf("node %s needs an avaBin or versions.avalanchego", n.Name)
+		}
+		nodes[n.Name] = n
+	}
+	subnets := map[string]bool{}
+	for _, s := range m.Subnets {
+		if _, ok := nodes[s.Node]; !ok {
+			return fmt.Errorf("subnet %s refers to unknown node %q", s.Name, s.Node)
+		}
+		subnets[s.Name] = true
+	}
+	for _, c := range m.Chains {
+		node, ok := nodes[c.Node]
+		if !ok {
+			return fmt.Errorf("chain %s refers to unknown node %q", c.Name, c.Node)
+		}
+		if !subnets[c.Subnet] {
+			return fmt.Errorf("chain %s refers to unknown subnet %q", c.Name, c.Subnet)
+		}
+		if _, ok := node.VMs[c.VM]; !ok {
+			return fmt.Errorf("chain %s uses vm %q which is not installed on node %s", c.Name, c.VM, c.Node)
+		}
+		if c.Genesis == "" {
+			return fmt.Errorf("chain %s is missing a genesis file", c.Name)
+		}
+	}
+	return nil
+}
+
+// VMNames returns the node's vm names in a stable order
+func (n Node) VMNames() []string {
+	names := []string{}
+	for name := range n.VMs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadLock returns an empty Lock if the file does not exist yet
+func LoadLock(path string) (*Lock, error) {
+	lock := &Lock{
+		Subnets: map[string]LockedSubnet{},
+		Chains:  map[string]LockedChain{},
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, lock); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+	return lock, nil
+}
+
+func (l *Lock) Save(path string) error {
+	b, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileBytes(path, b)
+}
@@ -0,0 +1,61 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Load(t *testing.T) {
+	yml := `
+versions:
+  avalanchego: v1.9.7
+nodes:
+  - name: NodeV1
+    vms:
+      subnetevm: subnet-evm-v0.4.8
+      othervm: other-vm
+subnets:
+  - name: MySubnet
+    node: NodeV1
+chains:
+  - name: MyChain
+    node: NodeV1
+    subnet: MySubnet
+    vm: subnetevm
+    genesis: subnetevm-genesis.json
+    alloc:
+      alice: 10ether
+`
+	fn := filepath.Join(t.TempDir(), ManifestFilename)
+	require.NoError(t, os.WriteFile(fn, []byte(yml), 0o600))
+
+	m, err := Load(fn)
+	require.NoError(t, err)
+	require.Equal(t, []string{"othervm", "subnetevm"}, m.Nodes[0].VMNames())
+	require.Equal(t, "10ether", m.Chains[0].Alloc["alice"])
+}
+
+func Test_ValidateUnknownVM(t *testing.T) {
+	m := &Manifest{
+		Versions: map[string]string{"avalanchego": "v1.9.7"},
+		Nodes:    []Node{{Name: "NodeV1"}},
+		Subnets:  []Subnet{{Name: "MySubnet", Node: "NodeV1"}},
+		Chains:   []Chain{{Name: "MyChain", Node: "NodeV1", Subnet: "MySubnet", VM: "subnetevm", Genesis: "g.json"}},
+	}
+	require.ErrorContains(t, m.Validate(), "not installed on node NodeV1")
+}
+
+func Test_LockRoundTrip(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), LockFilename)
+	lock, err := LoadLock(fn)
+	require.NoError(t, err)
+	lock.Subnets["MySubnet"] = LockedSubnet{ID: "abc", Node: "NodeV1"}
+	require.NoError(t, lock.Save(fn))
+
+	lock, err = LoadLock(fn)
+	require.NoError(t, err)
+	require.Equal(t, "abc", lock.Subnets["MySubnet"].ID)
+}
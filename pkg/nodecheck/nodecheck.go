@@ -0,0 +1,352 @@
+// Package nodecheck validates a node work-dir's configs against what dijetsnode
+// and its VMs accept, so mistakes are found before the node exits on them.
+package nodecheck
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lasthyphen/dijetsnode/config"
+	"github.com/lasthyphen/dijetsnode/utils/constants"
+	"github.com/lasthyphen/dijetsnode/vms/avm"
+	"github.com/lasthyphen/ecctools/pkg/genesis"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/lasthyphen/utilitychain/plugin/evm"
+	"github.com/tidwall/gjson"
+)
+
+type Problem struct {
+	File string `json:"file"`
+	// Top level key in File, empty if the problem is with the whole file
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+	// Warnings don't stop the node from starting
+	Warning bool `json:"warning,omitempty"`
+}
+
+func (p Problem) String() string {
+	loc := p.File
+	if p.Path != "" {
+		loc = fmt.Sprintf("%s: %s", p.File, p.Path)
+	}
+	return fmt.Sprintf("%s: %s", loc, p.Message)
+}
+
+type Problems []Problem
+
+// Errors are the problems that will stop the node from starting
+func (ps Problems) Errors() Problems {
+	out := Problems{}
+	for _, p := range ps {
+		if !p.Warning {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Keys subnet-evm accepts in its chain config on top of utilitychain's
+var subnetEVMKeys = map[string]bool{"feeRecipient": true}
+
+// Check validates the node config, chain configs and genesis in workDir
+func Check(workDir string) (Problems, error) {
+	fileLocations := utils.NewFileLocations(workDir)
+	layout := utils.NewDirectoryLayout(workDir)
+	problems := Problems{}
+
+	nodeConfig, ps, err := checkNodeConfig(fileLocations.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	problems = append(problems, ps...)
+
+	if utils.FileExists(fileLocations.CChainConfigFile) {
+		cfg := &evm.Config{}
+		cfg.SetDefaults()
+		problems = append(problems, checkStruct(fileLocations.CChainConfigFile, cfg, nil)...)
+	}
+	if utils.FileExists(fileLocations.XChainConfigFile) {
+		problems = append(problems, checkStruct(fileLocations.XChainConfigFile, &avm.Config{}, nil)...)
+	}
+
+	entries, err := os.ReadDir(layout.ChainConfigDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, e := range entries {
+		if !e.IsDir() || e.Name() == "C" || e.Name() == "X" {
+			continue
+		}
+		fn := filepath.Join(layout.ChainConfigDir, e.Name(), "config.json")
+		if !utils.FileExists(fn) {
+			continue
+		}
+		cfg := &evm.Config{}
+		cfg.SetDefaults()
+		ps := checkStruct(fn, cfg, subnetEVMKeys)
+		for i := range ps {
+			if ps[i].Warning {
+				ps[i].Message += " (ignore this if the chain is not an EVM)"
+			}
+		}
+		problems = append(problems, ps...)
+	}
+
+	if utils.FileExists(fileLocations.AvaGenesisFile) {
+		problems = append(problems, checkGenesis(fileLocations.AvaGenesisFile, nodeConfig)...)
+	}
+	return problems, nil
+}
+
+// checkNodeConfig checks every key is a dijetsnode flag and its value parses as that flag's type
+func checkNodeConfig(fn string) (map[string]json.RawMessage, Problems, error) {
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return nil, Problems{{File: fn, Message: fmt.Sprintf("invalid JSON: %s", err)}}, nil
+	}
+
+	fs := config.BuildFlagSet()
+	problems := Problems{}
+	for _, key := range sortedKeys(cfg) {
+		f := fs.Lookup(key)
+		if f == nil {
+			problems = append(problems, Problem{File: fn, Path: key, Message: "unknown key" + suggest(key, flagNames(fs)), Warning: true})
+			continue
+		}
+		if err := checkFlagValue(f, cfg[key]); err != nil {
+			problems = append(problems, Problem{File: fn, Path: key, Message: err.Error()})
+		}
+	}
+	return cfg, problems, nil
+}
+
+func checkFlagValue(f *flag.Flag, raw json.RawMessage) error {
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return err
+	}
+	s := ""
+	switch val := v.(type) {
+	case string:
+		s = val
+	case bool:
+		s = fmt.Sprint(val)
+	case float64:
+		s = strings.TrimSpace(string(raw))
+	default:
+		return fmt.Errorf("expected a %s, got %s", flagType(f), raw)
+	}
+
+	if f.Name == config.NetworkNameKey {
+		_, err := constants.NetworkID(s)
+		return err
+	}
+
+	getter, ok := f.Value.(flag.Getter)
+	if !ok {
+		return nil
+	}
+	switch getter.Get().(type) {
+	case time.Duration:
+		// viper reads numbers as nanoseconds
+		if _, ok := v.(float64); ok {
+			return nil
+		}
+		if _, err := time.ParseDuration(s); err != nil {
+			return fmt.Errorf("expected a duration like 10s, got %s", raw)
+		}
+	case string:
+	default:
+		if err := f.Value.Set(s); err != nil {
+			return fmt.Errorf("expected a %s, got %s", flagType(f), raw)
+		}
+	}
+	return nil
+}
+
+func flagType(f *flag.Flag) string {
+	getter, ok := f.Value.(flag.Getter)
+	if !ok {
+		return "value"
+	}
+	switch getter.Get().(type) {
+	case bool:
+		return "bool"
+	case int, int64, uint, uint64:
+		return "integer"
+	case float64:
+		return "number"
+	case time.Duration:
+		return "duration"
+	}
+	return "string"
+}
+
+// checkStruct checks the keys and values of a chain config against the VM's
+// config struct, and runs its Validate() if it has one.
+func checkStruct(fn string, target interface{}, extraKeys map[string]bool) Problems {
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return Problems{{File: fn, Message: err.Error()}}
+	}
+	cfg := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return Problems{{File: fn, Message: fmt.Sprintf("invalid JSON: %s", err)}}
+	}
+
+	known := jsonKeys(reflect.TypeOf(target).Elem())
+	problems := Problems{}
+	for _, key := range sortedKeys(cfg) {
+		if !known[key] {
+			if !extraKeys[key] {
+				problems = append(problems, Problem{File: fn, Path: key, Message: "unknown key" + suggest(key, known), Warning: true})
+			}
+			continue
+		}
+		single, _ := json.Marshal(map[string]json.RawMessage{key: cfg[key]})
+		fresh := reflect.New(reflect.TypeOf(target).Elem()).Interface()
+		if err := json.Unmarshal(single, fresh); err != nil {
+			problems = append(problems, Problem{File: fn, Path: key, Message: fmt.Sprintf("invalid value %s: %s", cfg[key], err)})
+		}
+	}
+	if len(problems.Errors()) > 0 {
+		return problems
+	}
+
+	if err := json.Unmarshal(b, target); err != nil {
+		return append(problems, Problem{File: fn, Message: err.Error()})
+	}
+	if v, ok := target.(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			problems = append(problems, Problem{File: fn, Message: err.Error()})
+		}
+	}
+	return problems
+}
+
+// checkGenesis parses the genesis the way the node will, and checks it is for the configured network
+func checkGenesis(fn string, nodeConfig map[string]json.RawMessage) Problems {
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return Problems{{File: fn, Message: err.Error()}}
+	}
+	if err := genesis.ValidatePrimary(b); err != nil {
+		return Problems{{File: fn, Message: err.Error()}}
+	}
+	raw, ok := nodeConfig[config.NetworkNameKey]
+	if !ok {
+		return nil
+	}
+	name := strings.Trim(string(raw), `"`)
+	networkID, err := constants.NetworkID(name)
+	if err != nil {
+		// Already reported for the node config
+		return nil
+	}
+	if genesisID := uint32(gjson.GetBytes(b, "networkID").Uint()); genesisID != networkID {
+		return Problems{{File: fn, Path: "networkID", Message: fmt.Sprintf("is %d but %s is %s in the node config", genesisID, config.NetworkNameKey, name)}}
+	}
+	return nil
+}
+
+// jsonKeys are the JSON keys of struct type t, including embedded structs
+func jsonKeys(t reflect.Type) map[string]bool {
+	keys := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			for k := range jsonKeys(f.Type) {
+				keys[k] = true
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if tag == "" {
+			tag = f.Name
+		}
+		keys[tag] = true
+	}
+	return keys
+}
+
+func flagNames(fs *flag.FlagSet) map[string]bool {
+	names := map[string]bool{}
+	fs.VisitAll(func(f *flag.Flag) { names[f.Name] = true })
+	return names
+}
+
+// suggest returns a "did you mean" for the closest known key, if any is close
+func suggest(key string, known map[string]bool) string {
+	names := make([]string, 0, len(known))
+	for k := range known {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	best, bestDist := "", 3
+	for _, k := range names {
+		if d := levenshtein(strings.ToLower(key), strings.ToLower(k)); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(", did you mean %s?", strconv.Quote(best))
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func minInt(vals ...int) int {
+	m := vals[0]
+	for _, v := range vals[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+func sortedKeys(m map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,41 @@
+package nodecheck
+
+import (
+	"testing"
+
+	"github.com/lasthyphen/ecctools/pkg/configs"
+	"github.com/lasthyphen/ecctools/pkg/testutil"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Check(t *testing.T) {
+	workDir := t.TempDir()
+	fileLocations := utils.NewFileLocations(workDir)
+	testutil.WriteFile(t, fileLocations.ConfigFile, `{"network-id":"network1337","api-admin-enable":true,"http-port":"96x0"}`)
+	testutil.WriteFile(t, fileLocations.CChainConfigFile, `{"pruning-enabled":"yes","local-txs-enabled":true}`)
+	testutil.WriteFile(t, fileLocations.XChainConfigFile, `{"index-transactions":true}`)
+
+	problems, err := Check(workDir)
+	require.NoError(t, err)
+	require.Len(t, problems, 4)
+	require.Equal(t, "api-admin-enable", problems[0].Path)
+	require.True(t, problems[0].Warning)
+	require.Contains(t, problems[0].Message, `did you mean "api-admin-enabled"`)
+	require.Equal(t, "http-port", problems[1].Path)
+	require.Equal(t, "network-id", problems[2].Path)
+	require.Equal(t, fileLocations.CChainConfigFile, problems[3].File)
+	require.Equal(t, "pruning-enabled", problems[3].Path)
+	require.Len(t, problems.Errors(), 3)
+
+	// A freshly prepared node has no errors
+	workDir = t.TempDir()
+	fileLocations = utils.NewFileLocations(workDir)
+	testutil.WriteFile(t, fileLocations.ConfigFile, configs.NodeConfig)
+	testutil.WriteFile(t, fileLocations.CChainConfigFile, configs.CChainConfig)
+	testutil.WriteFile(t, fileLocations.XChainConfigFile, configs.XChainConfig)
+	testutil.WriteFile(t, fileLocations.AvaGenesisFile, string(configs.AvaGenesis))
+	problems, err = Check(workDir)
+	require.NoError(t, err)
+	require.Empty(t, problems.Errors())
+}
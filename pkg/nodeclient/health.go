@@ -0,0 +1,63 @@
+package nodeclient
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lasthyphen/dijetsnode/api/health"
+)
+
+// WaitHealthy polls health.health every freq until the node and all its chains
+// report healthy, or ctx is done. The error then names the failing checks.
+// Checks named in ignore may fail.
+func (c *Client) WaitHealthy(ctx context.Context, freq time.Duration, ignore ...string) (*health.APIReply, error) {
+	ticker := time.NewTicker(freq)
+	defer ticker.Stop()
+
+	var last *health.APIReply
+	var lastErr error
+	for {
+		reply, err := c.Health.Health(ctx)
+		if err == nil && len(FailingChecks(reply, ignore...)) == 0 {
+			return reply, nil
+		}
+		if err == nil {
+			last = reply
+		} else if ctx.Err() == nil {
+			lastErr = err
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			if last != nil {
+				return last, fmt.Errorf("node is not healthy, failing checks: %s", strings.Join(FailingChecks(last, ignore...), "; "))
+			}
+			return nil, fmt.Errorf("node did not respond to health.health: %v", lastErr)
+		}
+	}
+}
+
+// FailingChecks returns "name: error" for each failing check not in ignore, sorted by name
+func FailingChecks(reply *health.APIReply, ignore ...string) []string {
+	out := []string{}
+	for name, result := range reply.Checks {
+		if result.Error != nil && !contains(ignore, name) {
+			out = append(out, fmt.Sprintf("%s: %s", name, *result.Error))
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
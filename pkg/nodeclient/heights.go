@@ -0,0 +1,31 @@
+package nodeclient
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// Heights returns the last accepted block height of each chain (ID or alias)
+// that answers, P via platform.getHeight and the rest via eth_blockNumber.
+// Chains that don't, like X, are left out.
+func (c *Client) Heights(ctx context.Context, chains []string) map[string]uint64 {
+	out := map[string]uint64{}
+	if h, err := c.P.GetHeight(ctx); err == nil {
+		out["P"] = h
+	}
+	for _, chain := range chains {
+		if chain == "P" {
+			continue
+		}
+		client, err := ethclient.DialContext(ctx, c.RPC(chain))
+		if err != nil {
+			continue
+		}
+		if h, err := client.BlockNumber(ctx); err == nil {
+			out[chain] = h
+		}
+		client.Close()
+	}
+	return out
+}
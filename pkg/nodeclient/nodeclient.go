@@ -0,0 +1,217 @@
+// Package nodeclient is a typed client for the node APIs ggt reads from, built
+// on the API clients that ship with dijetsnode.
+package nodeclient
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/lasthyphen/dijetsnode/api/admin"
+	"github.com/lasthyphen/dijetsnode/api/health"
+	"github.com/lasthyphen/dijetsnode/api/info"
+	"github.com/lasthyphen/dijetsnode/ids"
+	"github.com/lasthyphen/dijetsnode/utils/constants"
+	"github.com/lasthyphen/dijetsnode/utils/formatting/address"
+	"github.com/lasthyphen/dijetsnode/vms/platformvm"
+)
+
+type Client struct {
+	URI    string
+	Info   info.Client
+	P      platformvm.Client
+	Admin  admin.Client
+	Health health.Client
+}
+
+func New(uri string) *Client {
+	return &Client{
+		URI:    uri,
+		Info:   info.NewClient(uri),
+		P:      platformvm.NewClient(uri),
+		Admin:  admin.NewClient(uri),
+		Health: health.NewClient(uri),
+	}
+}
+
+// NodeInfo is everything `ggt node info` shows. Calls that failed are listed
+// in Errors and their fields left empty.
+type NodeInfo struct {
+	NodeID          ids.NodeID                 `json:"nodeID"`
+	NetworkID       uint32                     `json:"networkID"`
+	NetworkName     string                     `json:"networkName"`
+	Uptime          *info.UptimeResponse       `json:"uptime,omitempty"`
+	NodeVersion     *info.GetNodeVersionReply  `json:"getNodeVersion,omitempty"`
+	VMs             VMs                        `json:"getVMs"`
+	Subnets         []Subnet                   `json:"subnets"`
+	StakingAssetIDs map[ids.ID]ids.ID          `json:"stakingAssetIDs"`
+	Blockchains     []platformvm.APIBlockchain `json:"blockchains"`
+	Aliases         Aliases                    `json:"aliases"`
+	// chain name => RPC URL, C and every subnet chain
+	RPCs   map[string]string `json:"rpcs"`
+	Errors map[string]string `json:"errors,omitempty"`
+
+	mu sync.Mutex
+}
+
+type VMs struct {
+	VMs map[ids.ID][]string `json:"vms"`
+}
+
+type Subnet struct {
+	ID          ids.ID   `json:"id"`
+	ControlKeys []string `json:"controlKeys"`
+	Threshold   uint32   `json:"threshold"`
+}
+
+type Aliases struct {
+	BlockchainAliases map[ids.ID][]string `json:"blockchainAliases"`
+}
+
+func (i *NodeInfo) addError(call string, err error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.Errors[call] = err.Error()
+}
+
+// GetInfo only fails if the node can't be reached at all. The other calls are
+// made concurrently, since there are a few per subnet and blockchain.
+func (c *Client) GetInfo(ctx context.Context) (*NodeInfo, error) {
+	i := &NodeInfo{
+		StakingAssetIDs: map[ids.ID]ids.ID{},
+		Aliases:         Aliases{BlockchainAliases: map[ids.ID][]string{}},
+		RPCs:            map[string]string{"C": c.RPC("C")},
+		Errors:          map[string]string{},
+	}
+
+	var err error
+	i.NodeID, _, err = c.Info.GetNodeID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to reach node at %s: %w", c.URI, err)
+	}
+
+	wg := sync.WaitGroup{}
+	run := func(call string, f func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := f(); err != nil {
+				i.addError(call, err)
+			}
+		}()
+	}
+
+	run("info.getNetworkName", func() (err error) {
+		i.NetworkName, err = c.Info.GetNetworkName(ctx)
+		return err
+	})
+	run("info.uptime", func() (err error) {
+		i.Uptime, err = c.Info.Uptime(ctx, constants.PrimaryNetworkID)
+		return err
+	})
+	run("info.getNodeVersion", func() (err error) {
+		i.NodeVersion, err = c.Info.GetNodeVersion(ctx)
+		return err
+	})
+	run("info.getVMs", func() (err error) {
+		i.VMs.VMs, err = c.Info.GetVMs(ctx)
+		return err
+	})
+	run("platform.getSubnets", func() error {
+		networkID, err := c.Info.GetNetworkID(ctx)
+		if err != nil {
+			i.addError("info.getNetworkID", err)
+			return nil
+		}
+		i.NetworkID = networkID
+		if i.Subnets, err = c.GetSubnets(ctx, constants.GetHRP(networkID)); err != nil {
+			return err
+		}
+		for _, s := range i.Subnets {
+			subnetID := s.ID
+			run(fmt.Sprintf("platform.getStakingAssetID %s", subnetID), func() error {
+				assetID, err := c.P.GetStakingAssetID(ctx, subnetID)
+				if err != nil {
+					return err
+				}
+				i.mu.Lock()
+				defer i.mu.Unlock()
+				i.StakingAssetIDs[subnetID] = assetID
+				return nil
+			})
+		}
+		return nil
+	})
+	run("platform.getBlockchains", func() (err error) {
+		if i.Blockchains, err = c.P.GetBlockchains(ctx); err != nil {
+			return err
+		}
+		for _, b := range i.Blockchains {
+			if b.SubnetID != constants.PrimaryNetworkID {
+				i.mu.Lock()
+				i.RPCs[b.Name] = c.RPC(b.ID.String())
+				i.mu.Unlock()
+			}
+			// Fails if the admin API is disabled on this node
+			chainID := b.ID
+			run(fmt.Sprintf("admin.getChainAliases %s", chainID), func() error {
+				aliases, err := c.Admin.GetChainAliases(ctx, chainID.String())
+				if err != nil {
+					return err
+				}
+				// If the chain didn't start for some reason, it has no aliases
+				if len(aliases) == 0 {
+					aliases = []string{"blockchain not started, check logs"}
+				}
+				i.mu.Lock()
+				defer i.mu.Unlock()
+				i.Aliases.BlockchainAliases[chainID] = aliases
+				return nil
+			})
+		}
+		return nil
+	})
+	wg.Wait()
+
+	return i, nil
+}
+
+// GetSubnets returns all subnets with their control keys formatted as P-chain addresses
+func (c *Client) GetSubnets(ctx context.Context, hrp string) ([]Subnet, error) {
+	subnets, err := c.P.GetSubnets(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	out := []Subnet{}
+	for _, s := range subnets {
+		subnet := Subnet{ID: s.ID, ControlKeys: []string{}, Threshold: s.Threshold}
+		for _, key := range s.ControlKeys {
+			addr, err := address.Format("P", hrp, key.Bytes())
+			if err != nil {
+				return nil, err
+			}
+			subnet.ControlKeys = append(subnet.ControlKeys, addr)
+		}
+		out = append(out, subnet)
+	}
+	return out, nil
+}
+
+// FindBlockchain returns the blockchain called name, or nil if there is none
+func (c *Client) FindBlockchain(ctx context.Context, name string) (*platformvm.APIBlockchain, error) {
+	blockchains, err := c.P.GetBlockchains(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range blockchains {
+		if b.Name == name {
+			return &b, nil
+		}
+	}
+	return nil, nil
+}
+
+// RPC is the EVM JSON-RPC URL for a chain ID or alias
+func (c *Client) RPC(chain string) string {
+	return fmt.Sprintf("%s/ext/bc/%s/rpc", c.URI, chain)
+}
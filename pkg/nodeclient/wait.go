@@ -0,0 +1,121 @@
+package nodeclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/lasthyphen/dijetsnode/ids"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+)
+
+// WaitReady polls every freq until the node's primary network chains are
+// bootstrapped, health.health is healthy and, if chain (an ID, alias or name)
+// is given, that chain is bootstrapped and serving RPC, or until ctx is done.
+// The error then says what wasn't ready, including the failing health checks.
+// Health checks named in ignore don't count.
+func (c *Client) WaitReady(ctx context.Context, chain string, freq time.Duration, ignore ...string) error {
+	ticker := time.NewTicker(freq)
+	defer ticker.Stop()
+
+	notReady := []string{"no response yet"}
+	for {
+		reasons := c.NotReady(ctx, chain, ignore...)
+		if len(reasons) == 0 {
+			return nil
+		}
+		// Calls cut short by ctx say nothing about the node
+		if ctx.Err() == nil {
+			notReady = reasons
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("node at %s is not ready: %s", c.URI, strings.Join(notReady, "; "))
+		}
+	}
+}
+
+// NotReady returns why the node, and chain if it isn't empty, aren't ready, or nothing if they are.
+// Failing health checks named in ignore are left out.
+func (c *Client) NotReady(ctx context.Context, chain string, ignore ...string) []string {
+	if _, _, err := c.Info.GetNodeID(ctx); err != nil {
+		return []string{fmt.Sprintf("unable to reach node: %s", err)}
+	}
+
+	out := []string{}
+	chains := []string{"P", "X", "C"}
+	chainID := ""
+	if chain != "" {
+		id, err := c.resolveChain(ctx, chain)
+		if err != nil {
+			out = append(out, err.Error())
+		} else {
+			chainID = id
+			chains = append(chains, chainID)
+		}
+	}
+	for _, ch := range chains {
+		bootstrapped, err := c.Info.IsBootstrapped(ctx, ch)
+		if err != nil {
+			out = append(out, fmt.Sprintf("info.isBootstrapped %s: %s", ch, err))
+		} else if !bootstrapped {
+			out = append(out, fmt.Sprintf("%s is not bootstrapped", ch))
+		}
+	}
+
+	reply, err := c.Health.Health(ctx)
+	if err != nil {
+		out = append(out, fmt.Sprintf("health.health: %s", err))
+	} else if !reply.Healthy {
+		out = append(out, FailingChecks(reply, ignore...)...)
+	}
+
+	if chainID != "" {
+		if err := c.rpcReady(ctx, chainID); err != nil {
+			out = append(out, fmt.Sprintf("%s RPC is not serving: %s", chain, err))
+		}
+	}
+	return out
+}
+
+// IgnoredHealthChecks returns the health checks that can't pass for the node
+// prepared in workDir. A node without a staking cert runs without staking and
+// has no peers, so its network check only passes if start.sh sets
+// --network-health-min-conn-peers=0, which older node dirs don't.
+func IgnoredHealthChecks(workDir string) []string {
+	locs := utils.NewFileLocations(workDir)
+	if workDir == "" || !utils.FileExists(locs.ConfigFile) || utils.FileExists(locs.StakingCertFile) {
+		return nil
+	}
+	return []string{"network"}
+}
+
+// resolveChain turns a blockchain name into its ID. IDs and aliases (like C) are returned as is.
+func (c *Client) resolveChain(ctx context.Context, chain string) (string, error) {
+	if _, err := ids.FromString(chain); err == nil {
+		return chain, nil
+	}
+	b, err := c.FindBlockchain(ctx, chain)
+	if err != nil {
+		return "", fmt.Errorf("platform.getBlockchains: %w", err)
+	}
+	if b == nil {
+		// Not a name, maybe an alias
+		return chain, nil
+	}
+	return b.ID.String(), nil
+}
+
+func (c *Client) rpcReady(ctx context.Context, chainID string) error {
+	client, err := ethclient.DialContext(ctx, c.RPC(chainID))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	_, err = client.ChainID(ctx)
+	return err
+}
@@ -0,0 +1,367 @@
+// Package nodelogs reads, filters and follows a node's data/logs, merging the
+// main log and chain logs (and their rotated backups) by timestamp.
+package nodelogs
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+type Level int
+
+const (
+	LevelUnknown Level = iota
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelFatal
+)
+
+var levelNames = map[string]Level{
+	"verbo": LevelDebug,
+	"trace": LevelDebug,
+	"trce":  LevelDebug,
+	"debug": LevelDebug,
+	"dbug":  LevelDebug,
+	"info":  LevelInfo,
+	"warn":  LevelWarn,
+	"error": LevelError,
+	"eror":  LevelError,
+	"fatal": LevelFatal,
+	"crit":  LevelFatal,
+}
+
+func ParseLevel(s string) (Level, error) {
+	if l, ok := levelNames[strings.ToLower(s)]; ok {
+		return l, nil
+	}
+	return LevelUnknown, fmt.Errorf("unknown log level %q, expected debug, info, warn, error or fatal", s)
+}
+
+func (l Level) String() string {
+	return [...]string{"?", "DEBUG", "INFO", "WARN", "ERROR", "FATAL"}[l]
+}
+
+// Entry is one log line, plus any lines without a timestamp (like a stack trace) that follow it
+type Entry struct {
+	Time  time.Time
+	Level Level
+	// Log the entry came from, e.g. main, C or a chain ID
+	Source string
+	Text   string
+}
+
+var (
+	// dijetsnode's [01-02|15:04:05.000] and the VMs' geth style INFO [01-02|15:04:05.000]
+	termTimeRe = regexp.MustCompile(`\[(\d\d-\d\d\|\d\d:\d\d:\d\d\.\d{3})\]`)
+	levelRe    = regexp.MustCompile(`\b(VERBO|TRACE|TRCE|DEBUG|DBUG|INFO|WARN|ERROR|EROR|FATAL|CRIT)\b`)
+	ansiRe     = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	backupRe   = regexp.MustCompile(`^(.+)-\d{4}-\d\d-\d\dT\d\d-\d\d-\d\d\.\d{3}\.log(\.gz)?$`)
+)
+
+// Parse parses a line, ok is false if it has no timestamp and so continues the previous entry.
+// The logs leave out the year, so it is taken to be the last year that puts the time before now.
+func Parse(line string, now time.Time) (Entry, bool) {
+	line = ansiRe.ReplaceAllString(line, "")
+	e := Entry{Text: line}
+	if strings.HasPrefix(line, "{") {
+		t, err := time.Parse(time.RFC3339Nano, gjson.Get(line, "timestamp").String())
+		if err != nil {
+			return e, false
+		}
+		e.Time = t
+		e.Level, _ = ParseLevel(gjson.Get(line, "level").String())
+		return e, true
+	}
+
+	m := termTimeRe.FindStringSubmatchIndex(line)
+	if m == nil {
+		return e, false
+	}
+	t, err := time.ParseInLocation("2006-01-02|15:04:05.000", fmt.Sprintf("%d-%s", now.Year(), line[m[2]:m[3]]), time.Local)
+	if err != nil {
+		return e, false
+	}
+	if t.After(now.Add(24 * time.Hour)) {
+		t = t.AddDate(-1, 0, 0)
+	}
+	e.Time = t
+	if l := levelRe.FindString(line); l != "" {
+		e.Level, _ = ParseLevel(l)
+	}
+	return e, true
+}
+
+// Files returns the log files of source in logsDir, rotated backups (oldest
+// first) then the current file. dijetsnode rotates name.log to
+// name-<time>.log, gzipped if log-rotater-compress-enabled is set.
+func Files(logsDir string, source string) ([]string, error) {
+	backups, err := filepath.Glob(filepath.Join(logsDir, glob(source)+"-*.log*"))
+	if err != nil {
+		return nil, err
+	}
+	out := []string{}
+	for _, fn := range backups {
+		if name, ok := backupOf(filepath.Base(fn)); ok && name == source {
+			out = append(out, fn)
+		}
+	}
+	// The timestamp in the name sorts chronologically
+	sort.Strings(out)
+	current := filepath.Join(logsDir, source+".log")
+	if _, err := os.Stat(current); err == nil {
+		out = append(out, current)
+	}
+	return out, nil
+}
+
+// Sources are the logs in logsDir, main first
+func Sources(logsDir string) ([]string, error) {
+	entries, err := os.ReadDir(logsDir)
+	if err != nil {
+		return nil, err
+	}
+	out := []string{}
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".log")
+		if _, backup := backupOf(e.Name()); !ok || backup || e.IsDir() || name == "main" {
+			continue
+		}
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return append([]string{"main"}, out...), nil
+}
+
+// backupOf is the name of the log fn is a rotated backup of
+func backupOf(fn string) (string, bool) {
+	m := backupRe.FindStringSubmatch(fn)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+func glob(s string) string {
+	return strings.NewReplacer("*", `\*`, "?", `\?`, "[", `\[`).Replace(s)
+}
+
+// Read parses every entry in fn
+func Read(fn string, source string) ([]Entry, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if strings.HasSuffix(fn, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %w", fn, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	entries := []Entry{}
+	last, err := scan(r, source, time.Now(), nil, func(e Entry) { entries = append(entries, e) })
+	if last != nil {
+		entries = append(entries, *last)
+	}
+	return entries, err
+}
+
+// scan emits the entries in r, adding lines without a timestamp to current
+// until the first entry starts. The last entry isn't emitted but returned, as
+// more of its lines may follow.
+func scan(r io.Reader, source string, now time.Time, current *Entry, emit func(Entry)) (*Entry, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		e, ok := Parse(scanner.Text(), now)
+		if !ok {
+			if current != nil {
+				current.Text += "\n" + e.Text
+			}
+			continue
+		}
+		if current != nil {
+			emit(*current)
+		}
+		e.Source = source
+		current = &e
+	}
+	return current, scanner.Err()
+}
+
+// Merge sorts the entries of several logs by time, keeping each log's own order for equal times
+func Merge(logs ...[]Entry) []Entry {
+	out := []Entry{}
+	for _, l := range logs {
+		out = append(out, l...)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time.Before(out[j].Time) })
+	return out
+}
+
+type Filter struct {
+	MinLevel Level
+	Grep     *regexp.Regexp
+	Since    time.Time
+}
+
+func (f Filter) Match(e Entry) bool {
+	if f.MinLevel != LevelUnknown && e.Level < f.MinLevel {
+		return false
+	}
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	return f.Grep == nil || f.Grep.MatchString(e.Text)
+}
+
+// Follow polls the current log file of each source for new entries until ctx
+// is done. Without sources it follows every log in logsDir, including logs of
+// chains created while following. A file that shrinks or is replaced has been
+// rotated and is read from the start. An entry is emitted once the next one
+// starts or its file stops growing, so lines that continue it (like a stack
+// trace) stay with it even if they are written a poll later.
+func Follow(ctx context.Context, logsDir string, sources []string, freq time.Duration, emit func(Entry)) error {
+	type tailed struct {
+		offset  int64
+		info    os.FileInfo
+		pending *Entry
+	}
+	files := map[string]*tailed{}
+	all := len(sources) == 0
+	if all {
+		var err error
+		if sources, err = Sources(logsDir); err != nil {
+			return err
+		}
+	}
+	for _, s := range sources {
+		t := &tailed{}
+		if info, err := os.Stat(filepath.Join(logsDir, s+".log")); err == nil {
+			t.offset, t.info = info.Size(), info
+		}
+		files[s] = t
+	}
+
+	ticker := time.NewTicker(freq)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		if all {
+			found, err := Sources(logsDir)
+			if err != nil {
+				return err
+			}
+			for _, s := range found {
+				if _, ok := files[s]; !ok {
+					// A new log, read it from the start
+					files[s] = &tailed{}
+					sources = append(sources, s)
+				}
+			}
+		}
+
+		batch := [][]Entry{}
+		for _, s := range sources {
+			t := files[s]
+			fn := filepath.Join(logsDir, s+".log")
+			info, err := os.Stat(fn)
+			if err != nil {
+				continue
+			}
+			entries := []Entry{}
+			if t.info != nil && (!os.SameFile(t.info, info) || info.Size() < t.offset) {
+				t.offset = 0
+				if t.pending != nil {
+					entries = append(entries, *t.pending)
+					t.pending = nil
+				}
+			}
+			t.info = info
+			if info.Size() == t.offset {
+				if t.pending != nil {
+					batch = append(batch, append(entries, *t.pending))
+					t.pending = nil
+				}
+				continue
+			}
+			pending, n, err := readFrom(fn, s, t.offset, t.pending, func(e Entry) { entries = append(entries, e) })
+			if err != nil {
+				return err
+			}
+			t.offset += n
+			t.pending = pending
+			batch = append(batch, entries)
+		}
+		for _, e := range Merge(batch...) {
+			emit(e)
+		}
+	}
+}
+
+// readFrom scans the complete lines after offset, continuing pending, and
+// returns the last entry and how many bytes the lines took
+func readFrom(fn string, source string, offset int64, pending *Entry, emit func(Entry)) (*Entry, int64, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return pending, 0, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return pending, 0, err
+	}
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return pending, 0, err
+	}
+	// Leave a partly written line for next time
+	end := strings.LastIndexByte(string(b), '\n') + 1
+	last, err := scan(strings.NewReader(string(b[:end])), source, time.Now(), pending, emit)
+	return last, int64(end), err
+}
+
+var (
+	sourceColors = []string{"36", "35", "34", "32", "33", "96", "95", "94"}
+	levelColors  = map[Level]string{LevelDebug: "2", LevelWarn: "33", LevelError: "31", LevelFatal: "1;31"}
+)
+
+// Format prefixes e with its source, padded to width, coloring the source
+// (one color per entry in sources) and warnings and errors if color is set
+func Format(e Entry, sources []string, width int, color bool) string {
+	prefix := fmt.Sprintf("%-*s |", width, e.Source)
+	text := e.Text
+	if !color {
+		return prefix + " " + text
+	}
+	for i, s := range sources {
+		if s == e.Source {
+			prefix = "\x1b[" + sourceColors[i%len(sourceColors)] + "m" + prefix + "\x1b[0m"
+			break
+		}
+	}
+	if c, ok := levelColors[e.Level]; ok {
+		text = "\x1b[" + c + "m" + text + "\x1b[0m"
+	}
+	return prefix + " " + text
+}
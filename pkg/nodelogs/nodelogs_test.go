@@ -0,0 +1,126 @@
+package nodelogs
+
+import (
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/lasthyphen/ecctools/pkg/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	now := time.Date(2023, 1, 2, 12, 0, 0, 0, time.Local)
+
+	e, ok := Parse(`[01-02|10:01:02.345] WARN health/health.go:91 check failed`, now)
+	require.True(t, ok)
+	require.Equal(t, LevelWarn, e.Level)
+	require.Equal(t, time.Date(2023, 1, 2, 10, 1, 2, 345e6, time.Local), e.Time)
+
+	// Written last year
+	e, ok = Parse(`EROR [12-31|23:59:59.000] <C Chain> core/blockchain.go:1 bad block`, now)
+	require.True(t, ok)
+	require.Equal(t, LevelError, e.Level)
+	require.Equal(t, 2022, e.Time.Year())
+
+	e, ok = Parse(`{"level":"info","timestamp":"2023-01-02T10:00:00.000Z","msg":"hi"}`, now)
+	require.True(t, ok)
+	require.Equal(t, LevelInfo, e.Level)
+
+	_, ok = Parse("\tgoroutine 1 [running]:", now)
+	require.False(t, ok)
+}
+
+func TestFilesAndMerge(t *testing.T) {
+	dir := t.TempDir()
+	testutil.WriteFile(t, filepath.Join(dir, "main.log"), "[01-02|10:00:03.000] INFO main 3\n")
+	testutil.WriteFile(t, filepath.Join(dir, "main-2023-01-02T10-00-02.000.log"), "[01-02|10:00:01.000] INFO main 1\n")
+	testutil.WriteFile(t, filepath.Join(dir, "C.log"), "INFO [01-02|10:00:02.000] C 2\npanic: oops\n\tstack\n")
+	testutil.WriteFile(t, filepath.Join(dir, "main-extra.log"), "[01-02|10:00:00.000] INFO not a backup\n")
+	f, err := os.Create(filepath.Join(dir, "main-2023-01-02T10-00-00.000.log.gz"))
+	require.NoError(t, err)
+	gz := gzip.NewWriter(f)
+	_, err = gz.Write([]byte("[01-02|10:00:00.000] ERROR main 0\n"))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	require.NoError(t, f.Close())
+
+	files, err := Files(dir, "main")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(dir, "main-2023-01-02T10-00-00.000.log.gz"),
+		filepath.Join(dir, "main-2023-01-02T10-00-02.000.log"),
+		filepath.Join(dir, "main.log"),
+	}, files)
+
+	sources, err := Sources(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"main", "C", "main-extra"}, sources)
+
+	logs := [][]Entry{}
+	for _, fn := range append(files, filepath.Join(dir, "C.log")) {
+		source := "main"
+		if filepath.Base(fn) == "C.log" {
+			source = "C"
+		}
+		entries, err := Read(fn, source)
+		require.NoError(t, err)
+		logs = append(logs, entries)
+	}
+	merged := Merge(logs...)
+	require.Len(t, merged, 4)
+	require.Equal(t, "[01-02|10:00:00.000] ERROR main 0", merged[0].Text)
+	require.Equal(t, "C", merged[2].Source)
+	require.Equal(t, "INFO [01-02|10:00:02.000] C 2\npanic: oops\n\tstack", merged[2].Text)
+
+	filter := Filter{MinLevel: LevelWarn}
+	require.True(t, filter.Match(merged[0]))
+	require.False(t, filter.Match(merged[1]))
+	filter = Filter{Grep: regexp.MustCompile("oops")}
+	require.True(t, filter.Match(merged[2]))
+	require.False(t, filter.Match(merged[3]))
+}
+
+func TestFollow(t *testing.T) {
+	dir := t.TempDir()
+	appendTo := func(name string, content string) {
+		f, err := os.OpenFile(filepath.Join(dir, name), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		require.NoError(t, err)
+		_, err = f.WriteString(content)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+	}
+
+	// A panic's stack written a poll after its first line stays with it
+	appendTo("main.log", "[01-02|10:00:00.000] INFO before\n[01-02|10:00:01.000] ERROR panic: oops\n")
+	emitted := []Entry{}
+	emit := func(e Entry) { emitted = append(emitted, e) }
+	pending, n, err := readFrom(filepath.Join(dir, "main.log"), "main", 0, nil, emit)
+	require.NoError(t, err)
+	require.Len(t, emitted, 1)
+	appendTo("main.log", "goroutine 1 [running]:\n\tmain.go:1\n[01-02|10:00:02.000] INFO after\n")
+	_, _, err = readFrom(filepath.Join(dir, "main.log"), "main", n, pending, emit)
+	require.NoError(t, err)
+	require.Len(t, emitted, 2)
+	require.Equal(t, "[01-02|10:00:01.000] ERROR panic: oops\ngoroutine 1 [running]:\n\tmain.go:1", emitted[1].Text)
+
+	// Without sources, logs of chains created while following are followed too
+	ctx, cancel := context.WithCancel(context.Background())
+	entries := make(chan Entry, 10)
+	done := make(chan error)
+	go func() { done <- Follow(ctx, dir, nil, 5*time.Millisecond, func(e Entry) { entries <- e }) }()
+	time.Sleep(50 * time.Millisecond)
+	appendTo("chainA.log", "INFO [01-02|10:00:03.000] new chain\n")
+	select {
+	case e := <-entries:
+		require.Equal(t, "chainA", e.Source)
+	case <-time.After(5 * time.Second):
+		t.Fatal("chainA.log wasn't followed")
+	}
+	cancel()
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,401 @@
+// Package snapshot saves and restores a node's data dir together with the
+// binaries it was linked to, so chain state can be rolled back.
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/lasthyphen/ecctools/pkg/vmcompat"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	DirName      = "snapshots"
+	metaFilename = "snapshot.json"
+	dataDirName  = "data"
+	// configs/chains, where create-chain puts chain configs and aliases
+	chainsDirName = "chains"
+	// Logs aren't state, and restoring them would confuse anyone tailing them
+	logsDirName = "logs"
+)
+
+var ErrNotFound = errors.New("snapshot not found")
+
+const trackSubnetsKey = "track-subnets"
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+type Snapshot struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Why it was taken, e.g. "before upgrade"
+	Reason string `json:"reason,omitempty"`
+	// Binary bin/dijetsnode linked to
+	AvaBin string `json:"avaBin"`
+	// Plugin file name (VM ID) => binary it linked to
+	Plugins map[string]string `json:"plugins"`
+	// Contents of configs/vms/aliases.json
+	VMAliases json.RawMessage `json:"vmAliases,omitempty"`
+	// track-subnets from node-config.json, add-subnet-validator sets it
+	TrackSubnets string `json:"trackSubnets,omitempty"`
+	// Versions the binaries reported when the snapshot was taken
+	Versions *vmcompat.Report `json:"versions,omitempty"`
+	Chains   []Chain          `json:"chains"`
+	// Bytes of data copied
+	Size int64 `json:"size"`
+}
+
+// Chain is a chain with a config dir or alias in configs/chains
+type Chain struct {
+	ID      string   `json:"id"`
+	Aliases []string `json:"aliases"`
+	// Nil if the node wasn't running to ask
+	Height *uint64 `json:"height,omitempty"`
+}
+
+type Options struct {
+	// Why the snapshot is taken, e.g. "before upgrade"
+	Reason string
+	// Chain ID or alias (P, C, X) => last accepted block height
+	Heights map[string]uint64
+}
+
+// Dir is where workDir's snapshots are kept
+func Dir(workDir string) string {
+	return filepath.Join(workDir, DirName)
+}
+
+func path(workDir string, name string) string {
+	return filepath.Join(Dir(workDir), name)
+}
+
+// Save copies workDir's data dir (except logs) into a new snapshot. The node
+// must be stopped, copying a live database gives a corrupt snapshot.
+func Save(workDir string, name string, opts Options) (*Snapshot, error) {
+	if !validName.MatchString(name) {
+		return nil, fmt.Errorf("invalid snapshot name %q, use letters, numbers, '.', '_' and '-'", name)
+	}
+	dir := path(workDir, name)
+	if _, err := os.Stat(dir); err == nil {
+		return nil, fmt.Errorf("snapshot %s already exists", name)
+	}
+
+	files := utils.NewFileLocations(workDir)
+	dirs := utils.NewDirectoryLayout(workDir)
+	s := &Snapshot{Name: name, CreatedAt: time.Now().UTC(), Reason: opts.Reason, Plugins: map[string]string{}}
+	s.AvaBin, _ = os.Readlink(files.AvaBinFile)
+	entries, err := os.ReadDir(dirs.PluginDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, e := range entries {
+		target, err := os.Readlink(filepath.Join(dirs.PluginDir, e.Name()))
+		if err != nil {
+			// Not a link, so there is nothing to restore it to
+			continue
+		}
+		s.Plugins[e.Name()] = target
+	}
+	if b, err := os.ReadFile(files.VMAliasesFile); err == nil && json.Valid(b) {
+		s.VMAliases = b
+	}
+	if b, err := os.ReadFile(files.ConfigFile); err == nil {
+		s.TrackSubnets = gjson.GetBytes(b, trackSubnetsKey).String()
+	}
+	if s.Versions, err = vmcompat.Check(workDir); err != nil {
+		return nil, err
+	}
+	if s.Chains, err = Chains(workDir, opts.Heights); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	size, err := utils.CopyDir(dirs.DataDir, filepath.Join(dir, dataDirName), func(rel string) bool { return rel == logsDirName })
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, fmt.Errorf("unable to copy %s: %w", dirs.DataDir, err)
+	}
+	s.Size = size
+	if _, err := utils.CopyDir(dirs.ChainConfigDir, filepath.Join(dir, chainsDirName), nil); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, fmt.Errorf("unable to copy %s: %w", dirs.ChainConfigDir, err)
+	}
+	if err := s.save(workDir); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Snapshot) save(workDir string) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(path(workDir, s.Name), metaFilename), b, 0644)
+}
+
+func Get(workDir string, name string) (*Snapshot, error) {
+	b, err := os.ReadFile(filepath.Join(path(workDir, name), metaFilename))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", name, ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s := &Snapshot{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("unable to parse snapshot %s: %w", name, err)
+	}
+	return s, nil
+}
+
+// List returns workDir's snapshots, oldest first
+func List(workDir string) ([]Snapshot, error) {
+	entries, err := os.ReadDir(Dir(workDir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return []Snapshot{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	out := []Snapshot{}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		s, err := Get(workDir, e.Name())
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *s)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+// Restore replaces workDir's data dir (except logs) with the snapshot's, and
+// if bins is set relinks the binaries and VM aliases as they were. The node
+// must be stopped.
+func Restore(workDir string, name string, bins bool) (*Snapshot, error) {
+	s, err := Get(workDir, name)
+	if err != nil {
+		return nil, err
+	}
+	dirs := utils.NewDirectoryLayout(workDir)
+
+	entries, err := os.ReadDir(dirs.DataDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.Name() == logsDirName {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(dirs.DataDir, e.Name())); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := utils.CopyDir(filepath.Join(path(workDir, name), dataDirName), dirs.DataDir, nil); err != nil {
+		return nil, fmt.Errorf("unable to restore %s: %w", dirs.DataDir, err)
+	}
+
+	// Snapshots taken before chain configs were saved don't have them
+	if saved := filepath.Join(path(workDir, name), chainsDirName); utils.DirExists(saved) {
+		if err := os.RemoveAll(dirs.ChainConfigDir); err != nil {
+			return nil, err
+		}
+		if _, err := utils.CopyDir(saved, dirs.ChainConfigDir, nil); err != nil {
+			return nil, fmt.Errorf("unable to restore %s: %w", dirs.ChainConfigDir, err)
+		}
+		if err := s.restoreTrackSubnets(workDir); err != nil {
+			return nil, err
+		}
+	}
+
+	if bins {
+		if err := s.relink(workDir); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Only track-subnets is restored, the rest of node-config.json (like ports) belongs to the work dir
+func (s *Snapshot) restoreTrackSubnets(workDir string) error {
+	fn := utils.NewFileLocations(workDir).ConfigFile
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return err
+	}
+	if s.TrackSubnets == "" {
+		b, err = sjson.DeleteBytes(b, trackSubnetsKey)
+	} else {
+		b, err = sjson.SetBytes(b, trackSubnetsKey, s.TrackSubnets)
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fn, b, 0644)
+}
+
+// Delete removes a snapshot
+func Delete(workDir string, name string) error {
+	if _, err := Get(workDir, name); err != nil {
+		return err
+	}
+	return os.RemoveAll(path(workDir, name))
+}
+
+// Check returns the reasons the node's current binaries can't run the
+// snapshot's data, empty if they can. The snapshot's own binaries always can.
+func (s *Snapshot) Check(workDir string) ([]string, error) {
+	current, err := vmcompat.Check(workDir)
+	if err != nil {
+		return nil, err
+	}
+	problems := current.Errors()
+	if s.Versions == nil {
+		return problems, nil
+	}
+	was := s.Versions.Node
+	if was.Database != "" && current.Node.Database != "" && was.Database != current.Node.Database {
+		problems = append(problems, fmt.Sprintf("the snapshot's data is database %s (%s) but %s is database %s",
+			was.Database, was.Version, current.Node.Path, current.Node.Database))
+	}
+	plugins := map[string]bool{}
+	for _, p := range current.Plugins {
+		plugins[p.Name] = true
+	}
+	for _, p := range s.Versions.Plugins {
+		if !plugins[p.Name] {
+			problems = append(problems, fmt.Sprintf("the snapshot has VM %s (%s) but the node has no plugin for it", p.Name, p.Version))
+		}
+	}
+	return problems, nil
+}
+
+// Chains lists the chains in workDir's chain configs and aliases, with their
+// heights if they are in heights (by ID or alias)
+func Chains(workDir string, heights map[string]uint64) ([]Chain, error) {
+	dirs := utils.NewDirectoryLayout(workDir)
+	files := utils.NewFileLocations(workDir)
+	byID := map[string]*Chain{}
+	chain := func(id string) *Chain {
+		if _, ok := byID[id]; !ok {
+			byID[id] = &Chain{ID: id, Aliases: []string{}}
+		}
+		return byID[id]
+	}
+
+	entries, err := os.ReadDir(dirs.ChainConfigDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			chain(e.Name())
+		}
+	}
+	if b, err := os.ReadFile(files.ChainAliasesFile); err == nil {
+		gjson.ParseBytes(b).ForEach(func(id, aliases gjson.Result) bool {
+			c := chain(id.String())
+			for _, a := range aliases.Array() {
+				c.Aliases = append(c.Aliases, a.String())
+			}
+			return true
+		})
+	}
+
+	// Heights of chains without a config, like P, get an entry of their own
+	for key := range heights {
+		found := byID[key] != nil
+		for _, c := range byID {
+			for _, alias := range c.Aliases {
+				found = found || alias == key
+			}
+		}
+		if !found {
+			chain(key)
+		}
+	}
+
+	out := []Chain{}
+	for id, c := range byID {
+		for _, key := range append([]string{id}, c.Aliases...) {
+			if h, ok := heights[key]; ok {
+				c.Height = &h
+				break
+			}
+		}
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// relink restores the binary and plugin links. Only links are replaced or
+// removed, real binaries (like copied or built plugins) weren't saved and are left alone.
+func (s *Snapshot) relink(workDir string) error {
+	files := utils.NewFileLocations(workDir)
+	dirs := utils.NewDirectoryLayout(workDir)
+
+	if s.AvaBin != "" {
+		if err := relink(s.AvaBin, files.AvaBinFile); err != nil {
+			return err
+		}
+	}
+	entries, err := os.ReadDir(dirs.PluginDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for _, e := range entries {
+		fn := filepath.Join(dirs.PluginDir, e.Name())
+		if _, ok := s.Plugins[e.Name()]; !ok && e.Type()&fs.ModeSymlink != 0 {
+			if err := os.Remove(fn); err != nil {
+				return err
+			}
+		}
+	}
+	for vmID, target := range s.Plugins {
+		if err := relink(target, filepath.Join(dirs.PluginDir, vmID)); err != nil {
+			return err
+		}
+	}
+	if len(s.VMAliases) > 0 {
+		return os.WriteFile(files.VMAliasesFile, s.VMAliases, 0644)
+	}
+	return nil
+}
+
+// relink points fn at target, target is used as is, like os.Readlink returned it.
+// A file that isn't a link is kept.
+func relink(target string, fn string) error {
+	if info, err := os.Lstat(fn); err == nil {
+		if info.Mode()&fs.ModeSymlink == 0 {
+			return nil
+		}
+		if err := os.Remove(fn); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+		return err
+	}
+	return os.Symlink(target, fn)
+}
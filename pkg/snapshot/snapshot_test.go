@@ -0,0 +1,98 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lasthyphen/ecctools/pkg/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func link(t *testing.T, target string, fn string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(fn), 0755))
+	_ = os.Remove(fn)
+	require.NoError(t, os.Symlink(target, fn))
+}
+
+func TestSaveRestore(t *testing.T) {
+	workDir := t.TempDir()
+	testutil.WriteFile(t, filepath.Join(workDir, "data", "db", "MANIFEST"), "v1")
+	testutil.WriteFile(t, filepath.Join(workDir, "data", "logs", "main.log"), "old log")
+	testutil.WriteFile(t, filepath.Join(workDir, "configs", "vms", "aliases.json"), `{"vmA":["a"]}`)
+	testutil.WriteFile(t, filepath.Join(workDir, "configs", "node-config.json"), `{"http-port":9650,"track-subnets":"subnetA"}`)
+	testutil.WriteFile(t, filepath.Join(workDir, "configs", "chains", "C", "config.json"), `{}`)
+	testutil.WriteFile(t, filepath.Join(workDir, "configs", "chains", "chainA", "config.json"), `{"v":1}`)
+	testutil.WriteFile(t, filepath.Join(workDir, "configs", "chains", "aliases.json"), `{"chainA":["a"]}`)
+	link(t, "/bins/ava-v1", filepath.Join(workDir, "bin", "dijetsnode"))
+	link(t, "/bins/vm-v1", filepath.Join(workDir, "bin", "plugins", "vmA"))
+
+	s, err := Save(workDir, "before", Options{Reason: "test", Heights: map[string]uint64{"P": 5, "a": 7}})
+	require.NoError(t, err)
+	require.Equal(t, "subnetA", s.TrackSubnets)
+	seven, five := uint64(7), uint64(5)
+	require.Equal(t, []Chain{
+		{ID: "C", Aliases: []string{}},
+		{ID: "P", Aliases: []string{}, Height: &five},
+		{ID: "chainA", Aliases: []string{"a"}, Height: &seven},
+	}, s.Chains)
+	require.Equal(t, "/bins/ava-v1", s.AvaBin)
+	require.Equal(t, map[string]string{"vmA": "/bins/vm-v1"}, s.Plugins)
+	require.Equal(t, int64(2), s.Size)
+	require.NoFileExists(t, filepath.Join(Dir(workDir), "before", "data", "logs", "main.log"))
+
+	_, err = Save(workDir, "before", Options{Reason: "test"})
+	require.ErrorContains(t, err, "already exists")
+	_, err = Save(workDir, "../escape", Options{})
+	require.ErrorContains(t, err, "invalid snapshot name")
+
+	// Upgrade
+	testutil.WriteFile(t, filepath.Join(workDir, "data", "db", "MANIFEST"), "v2")
+	testutil.WriteFile(t, filepath.Join(workDir, "data", "db", "NEW"), "v2")
+	testutil.WriteFile(t, filepath.Join(workDir, "data", "logs", "main.log"), "new log")
+	testutil.WriteFile(t, filepath.Join(workDir, "configs", "vms", "aliases.json"), `{"vmA":["a"],"vmB":["b"]}`)
+	link(t, "/bins/ava-v2", filepath.Join(workDir, "bin", "dijetsnode"))
+	link(t, "/bins/vm-v2", filepath.Join(workDir, "bin", "plugins", "vmA"))
+	link(t, "/bins/vmb", filepath.Join(workDir, "bin", "plugins", "vmB"))
+	testutil.WriteFile(t, filepath.Join(workDir, "bin", "plugins", "vmC"), "built")
+	testutil.WriteFile(t, filepath.Join(workDir, "configs", "node-config.json"), `{"http-port":9652,"track-subnets":"subnetA,subnetB"}`)
+	testutil.WriteFile(t, filepath.Join(workDir, "configs", "chains", "chainB", "config.json"), `{}`)
+
+	_, err = Restore(workDir, "before", true)
+	require.NoError(t, err)
+	b, err := os.ReadFile(filepath.Join(workDir, "data", "db", "MANIFEST"))
+	require.NoError(t, err)
+	require.Equal(t, "v1", string(b))
+	require.NoFileExists(t, filepath.Join(workDir, "data", "db", "NEW"))
+	b, err = os.ReadFile(filepath.Join(workDir, "data", "logs", "main.log"))
+	require.NoError(t, err)
+	require.Equal(t, "new log", string(b))
+
+	target, err := os.Readlink(filepath.Join(workDir, "bin", "dijetsnode"))
+	require.NoError(t, err)
+	require.Equal(t, "/bins/ava-v1", target)
+	target, err = os.Readlink(filepath.Join(workDir, "bin", "plugins", "vmA"))
+	require.NoError(t, err)
+	require.Equal(t, "/bins/vm-v1", target)
+	_, err = os.Lstat(filepath.Join(workDir, "bin", "plugins", "vmB"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+	// Plugins that aren't links weren't saved, so they are kept
+	require.FileExists(t, filepath.Join(workDir, "bin", "plugins", "vmC"))
+	b, err = os.ReadFile(filepath.Join(workDir, "configs", "vms", "aliases.json"))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"vmA":["a"]}`, string(b))
+	b, err = os.ReadFile(filepath.Join(workDir, "configs", "node-config.json"))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"http-port":9652,"track-subnets":"subnetA"}`, string(b))
+	require.FileExists(t, filepath.Join(workDir, "configs", "chains", "chainA", "config.json"))
+	require.NoDirExists(t, filepath.Join(workDir, "configs", "chains", "chainB"))
+
+	list, err := List(workDir)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	_, err = Get(workDir, "nope")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, Delete(workDir, "before"))
+	require.ErrorIs(t, Delete(workDir, "before"), ErrNotFound)
+}
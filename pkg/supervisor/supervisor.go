@@ -0,0 +1,254 @@
+package supervisor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/lasthyphen/ecctools/pkg/utils"
+)
+
+// State is written to each node's work dir by the process supervising the node
+// (`ggt node run`), so that other ggt commands can find, stop and restart it.
+type State struct {
+	Pid           int               `json:"pid"`
+	SupervisorPid int               `json:"supervisorPid"`
+	StartedAt     time.Time         `json:"startedAt"`
+	StoppedAt     *time.Time        `json:"stoppedAt,omitempty"`
+	ExitCode      *int              `json:"exitCode,omitempty"`
+	AvaBin        string            `json:"avaBin"`
+	Plugins       map[string]string `json:"plugins"`
+}
+
+// Status is State plus whether the node process is actually alive
+type Status struct {
+	State
+	Running bool `json:"running"`
+}
+
+var ErrNotRunning = errors.New("node is not running")
+
+// LoadState returns an empty State if the node has never been run
+func LoadState(workDir string) (*State, error) {
+	fn := utils.NewFileLocations(workDir).NodeStateFile
+	b, err := os.ReadFile(fn)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &State{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state := &State{}
+	if err := json.Unmarshal(b, state); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", fn, err)
+	}
+	return state, nil
+}
+
+func (s *State) Save(workDir string) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileBytes(utils.NewFileLocations(workDir).NodeStateFile, b)
+}
+
+// IsRunning is true if the node process recorded in the state is still alive
+func (s *State) IsRunning() bool {
+	return s.StoppedAt == nil && ProcessAlive(s.Pid)
+}
+
+// SupervisorRunning is true if the `ggt node run` process is still alive
+func (s *State) SupervisorRunning() bool {
+	return ProcessAlive(s.SupervisorPid)
+}
+
+func GetStatus(workDir string) (*Status, error) {
+	state, err := LoadState(workDir)
+	if err != nil {
+		return nil, err
+	}
+	return &Status{State: *state, Running: state.IsRunning()}, nil
+}
+
+// NewState records the binaries the node in workDir is about to be started with
+func NewState(workDir string) *State {
+	dirs := utils.NewDirectoryLayout(workDir)
+	files := utils.NewFileLocations(workDir)
+
+	state := &State{
+		SupervisorPid: os.Getpid(),
+		StartedAt:     time.Now(),
+		Plugins:       map[string]string{},
+	}
+	state.AvaBin, _ = filepath.EvalSymlinks(files.AvaBinFile)
+	entries, _ := os.ReadDir(dirs.PluginDir)
+	for _, e := range entries {
+		target, err := filepath.EvalSymlinks(filepath.Join(dirs.PluginDir, e.Name()))
+		if err != nil {
+			target = ""
+		}
+		state.Plugins[e.Name()] = target
+	}
+	return state
+}
+
+// MarkStopped records the exit status of the node process
+func (s *State) MarkStopped(exitCode int) {
+	now := time.Now()
+	s.StoppedAt = &now
+	s.ExitCode = &exitCode
+}
+
+func ProcessAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// StartDetached runs `ggt node run workDir` in its own session with output going
+// to the supervisor log in workDir, and waits for it to start the node.
+func StartDetached(workDir string, extraArgs ...string) (*State, error) {
+	state, err := LoadState(workDir)
+	if err != nil {
+		return nil, err
+	}
+	if state.IsRunning() {
+		return nil, fmt.Errorf("node in %s is already running with pid %d", workDir, state.Pid)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	logFile, err := os.OpenFile(utils.NewFileLocations(workDir).SupervisorLog, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	defer logFile.Close()
+
+	args := append([]string{"node", "run", workDir}, extraArgs...)
+	cmd := exec.Command(exe, args...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("unable to start supervisor: %w", err)
+	}
+	supervisorPid := cmd.Process.Pid
+	// We don't wait on the child, it outlives us
+	_ = cmd.Process.Release()
+
+	return waitForStart(workDir, supervisorPid, 30*time.Second)
+}
+
+func waitForStart(workDir string, supervisorPid int, timeout time.Duration) (*State, error) {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		state, err := LoadState(workDir)
+		if err == nil && state.SupervisorPid == supervisorPid && state.Pid != 0 {
+			if state.StoppedAt != nil {
+				return state, fmt.Errorf("node exited immediately with code %d", *state.ExitCode)
+			}
+			return state, nil
+		}
+		if !ProcessAlive(supervisorPid) {
+			return nil, fmt.Errorf("supervisor exited, see %s", utils.NewFileLocations(workDir).SupervisorLog)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+	return nil, fmt.Errorf("timed out waiting for node to start, see %s", utils.NewFileLocations(workDir).SupervisorLog)
+}
+
+// Stop asks the supervisor (or the node itself if it has no supervisor) to shut
+// down gracefully, and kills the node's whole process group after timeout.
+func Stop(workDir string, timeout time.Duration) error {
+	state, err := LoadState(workDir)
+	if err != nil {
+		return err
+	}
+	if !state.IsRunning() {
+		return ErrNotRunning
+	}
+
+	if state.SupervisorRunning() {
+		_ = syscall.Kill(state.SupervisorPid, syscall.SIGTERM)
+	} else {
+		_ = syscall.Kill(-state.Pid, syscall.SIGTERM)
+	}
+
+	if waitForExit(state.Pid, timeout) {
+		return nil
+	}
+
+	// The node was started with its own process group, so take out everything it spawned
+	if err := syscall.Kill(-state.Pid, syscall.SIGKILL); err != nil && !errors.Is(err, syscall.ESRCH) {
+		return fmt.Errorf("unable to kill process group %d: %w", state.Pid, err)
+	}
+	if state.SupervisorRunning() {
+		_ = syscall.Kill(state.SupervisorPid, syscall.SIGKILL)
+	}
+	if !waitForExit(state.Pid, 5*time.Second) {
+		return fmt.Errorf("node with pid %d did not exit", state.Pid)
+	}
+
+	// Supervisor was killed before it could record anything
+	state.MarkStopped(-1)
+	return state.Save(workDir)
+}
+
+func waitForExit(pid int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if !ProcessAlive(pid) {
+			return true
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+	return !ProcessAlive(pid)
+}
+
+// Restart bounces a running node. If a supervisor is watching the node it is
+// sent USR1 so the node restarts in place, otherwise the node is stopped and
+// started again in the background.
+func Restart(workDir string, timeout time.Duration) (*State, error) {
+	state, err := LoadState(workDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if state.IsRunning() && state.SupervisorRunning() {
+		oldPid := state.Pid
+		if err := syscall.Kill(state.SupervisorPid, syscall.SIGUSR1); err != nil {
+			return nil, fmt.Errorf("unable to signal supervisor %d: %w", state.SupervisorPid, err)
+		}
+		if !waitForExit(oldPid, timeout) {
+			_ = syscall.Kill(-oldPid, syscall.SIGKILL)
+		}
+		deadline := time.Now().Add(timeout + 30*time.Second)
+		for time.Now().Before(deadline) {
+			state, err = LoadState(workDir)
+			if err == nil && state.Pid != oldPid && state.Pid != 0 {
+				return state, nil
+			}
+			time.Sleep(250 * time.Millisecond)
+		}
+		return nil, fmt.Errorf("timed out waiting for node to restart")
+	}
+
+	if state.IsRunning() {
+		if err := Stop(workDir, timeout); err != nil {
+			return nil, err
+		}
+	}
+	return StartDetached(workDir)
+}
@@ -0,0 +1,17 @@
+// Package testutil has helpers shared by the package tests
+package testutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// WriteFile writes content to fn, creating its dir
+func WriteFile(t testing.TB, fn string, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(fn), 0755))
+	require.NoError(t, os.WriteFile(fn, []byte(content), 0644))
+}
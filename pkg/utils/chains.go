@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/lasthyphen/dijetsnode/ids"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// CreatedChains are the chains create-chain installed into workDir, those with
+// a config dir or alias in configs/chains named after their ID. Primary network
+// chains are configured under their aliases (C, X) and aren't included.
+func CreatedChains(workDir string) ([]ids.ID, error) {
+	found := map[ids.ID]bool{}
+	entries, err := os.ReadDir(NewDirectoryLayout(workDir).ChainConfigDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, e := range entries {
+		if id, err := ids.FromString(e.Name()); err == nil && e.IsDir() {
+			found[id] = true
+		}
+	}
+	if b, err := os.ReadFile(NewFileLocations(workDir).ChainAliasesFile); err == nil {
+		gjson.ParseBytes(b).ForEach(func(key, _ gjson.Result) bool {
+			if id, err := ids.FromString(key.String()); err == nil {
+				found[id] = true
+			}
+			return true
+		})
+	}
+
+	out := make([]ids.ID, 0, len(found))
+	for id := range found {
+		out = append(out, id)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].String() < out[j].String() })
+	return out, nil
+}
+
+// RemoveChainConfigs removes the config dirs and aliases of chainIDs from
+// workDir. If archiveDir isn't empty they are moved there instead, with the
+// removed aliases in archiveDir/aliases.json.
+func RemoveChainConfigs(workDir string, chainIDs []ids.ID, archiveDir string) error {
+	chainConfigDir := NewDirectoryLayout(workDir).ChainConfigDir
+	aliasesFile := NewFileLocations(workDir).ChainAliasesFile
+	if archiveDir != "" {
+		if err := os.MkdirAll(archiveDir, 0755); err != nil {
+			return err
+		}
+	}
+
+	aliases, err := os.ReadFile(aliasesFile)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	archived := []byte("{}")
+	for _, id := range chainIDs {
+		dir := filepath.Join(chainConfigDir, id.String())
+		if DirExists(dir) {
+			if archiveDir == "" {
+				err = os.RemoveAll(dir)
+			} else {
+				err = os.Rename(dir, filepath.Join(archiveDir, id.String()))
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if entry := gjson.GetBytes(aliases, id.String()); entry.Exists() {
+			if archived, err = sjson.SetRawBytes(archived, id.String(), []byte(entry.Raw)); err != nil {
+				return err
+			}
+			if aliases, err = sjson.DeleteBytes(aliases, id.String()); err != nil {
+				return err
+			}
+		}
+	}
+
+	if aliases != nil {
+		if err := os.WriteFile(aliasesFile, aliases, 0644); err != nil {
+			return err
+		}
+	}
+	if archiveDir != "" {
+		return os.WriteFile(filepath.Join(archiveDir, "aliases.json"), archived, 0644)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lasthyphen/dijetsnode/ids"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RemoveChainConfigs(t *testing.T) {
+	workDir := t.TempDir()
+	chainA, chainB := ids.GenerateTestID(), ids.GenerateTestID()
+	dirs := NewDirectoryLayout(workDir)
+	for _, name := range []string{"C", chainA.String(), chainB.String()} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dirs.ChainConfigDir, name), 0755))
+	}
+	aliasesFile := NewFileLocations(workDir).ChainAliasesFile
+	require.NoError(t, os.WriteFile(aliasesFile, []byte(`{"`+chainA.String()+`":["a"],"Other":["o"]}`), 0644))
+
+	chains, err := CreatedChains(workDir)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []ids.ID{chainA, chainB}, chains)
+
+	archive := filepath.Join(t.TempDir(), "archive")
+	require.NoError(t, RemoveChainConfigs(workDir, []ids.ID{chainA}, archive))
+	require.DirExists(t, filepath.Join(archive, chainA.String()))
+	require.NoDirExists(t, filepath.Join(dirs.ChainConfigDir, chainA.String()))
+	b, err := os.ReadFile(filepath.Join(archive, "aliases.json"))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"`+chainA.String()+`":["a"]}`, string(b))
+	b, err = os.ReadFile(aliasesFile)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"Other":["o"]}`, string(b))
+
+	require.NoError(t, RemoveChainConfigs(workDir, []ids.ID{chainB}, ""))
+	require.NoDirExists(t, filepath.Join(dirs.ChainConfigDir, chainB.String()))
+	require.DirExists(t, filepath.Join(dirs.ChainConfigDir, "C"))
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/viper"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	DefaultHTTPPort    = 9650
+	DefaultStakingPort = 9651
+	maxPortPairs       = 100
+)
+
+// NodePorts returns the http and staking ports from a node's node-config.json,
+// falling back to the avalanchego defaults.
+func NodePorts(workDir string) (httpPort int, stakingPort int) {
+	httpPort, stakingPort = DefaultHTTPPort, DefaultStakingPort
+	b, err := os.ReadFile(NewFileLocations(workDir).ConfigFile)
+	if err != nil {
+		return httpPort, stakingPort
+	}
+	cfg := gjson.ParseBytes(b)
+	if p := cfg.Get("http-port").Int(); p != 0 {
+		httpPort = int(p)
+	}
+	if p := cfg.Get("staking-port").Int(); p != 0 {
+		stakingPort = int(p)
+	}
+	return httpPort, stakingPort
+}
+
+// NodeURL is the API URL of the node prepared in workDir
+func NodeURL(workDir string) string {
+	httpPort, _ := NodePorts(workDir)
+	return fmt.Sprintf("http://127.0.0.1:%d", httpPort)
+}
+
+// ResolveNodeURL returns the URL for the node in workDir if it is a prepared
+// node dir, otherwise the global --node-url
+func ResolveNodeURL(workDir string) string {
+	if workDir != "" && FileExists(NewFileLocations(workDir).ConfigFile) {
+		return NodeURL(workDir)
+	}
+	return viper.GetString("node-url")
+}
+
+// AllocatePorts finds an http/staking port pair that is not configured by any
+// other node dir in projectDir and is currently free on this machine.
+func AllocatePorts(projectDir string) (httpPort int, stakingPort int, err error) {
+	used := map[int]bool{}
+	entries, err := os.ReadDir(projectDir)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, e := range entries {
+		dir := filepath.Join(projectDir, e.Name())
+		if !e.IsDir() || !FileExists(NewFileLocations(dir).ConfigFile) {
+			continue
+		}
+		h, s := NodePorts(dir)
+		used[h] = true
+		used[s] = true
+	}
+
+	for i := 0; i < maxPortPairs; i++ {
+		httpPort = DefaultHTTPPort + 2*i
+		stakingPort = httpPort + 1
+		if used[httpPort] || used[stakingPort] {
+			continue
+		}
+		if PortFree(httpPort) && PortFree(stakingPort) {
+			return httpPort, stakingPort, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("unable to find a free port pair starting at %d", DefaultHTTPPort)
+}
+
+func PortFree(port int) bool {
+	l, err := net.Listen("tcp", net.JoinHostPort("", strconv.Itoa(port)))
+	if err != nil {
+		return false
+	}
+	_ = l.Close()
+	return true
+}
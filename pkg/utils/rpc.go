@@ -0,0 +1,248 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/tidwall/gjson"
+)
+
+// RPCOptions configure how requests to the node are made
+type RPCOptions struct {
+	Timeout time.Duration
+	// Retries is how many times a request is retried after a connection
+	// error or a 5xx/429 response, with exponential backoff between
+	// RetryWait and RetryMaxWait. JSON-RPC errors are never retried.
+	Retries      int
+	RetryWait    time.Duration
+	RetryMaxWait time.Duration
+}
+
+var DefaultRPCOptions = RPCOptions{
+	Timeout:      30 * time.Second,
+	Retries:      3,
+	RetryWait:    250 * time.Millisecond,
+	RetryMaxWait: 4 * time.Second,
+}
+
+// RPCError is the error object of a JSON-RPC response
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+type RPCRequest struct {
+	Method string
+	// Params is marshalled to JSON, a string or []byte is used as raw JSON
+	Params any
+}
+
+type RPCResponse struct {
+	Result json.RawMessage
+	Error  *RPCError
+}
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      uint64          `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type rpcResponse struct {
+	ID     uint64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+// RPCClient is a JSON-RPC client that keeps connections to the node open
+// between requests. It is safe for concurrent use.
+type RPCClient struct {
+	client *resty.Client
+	id     atomic.Uint64
+}
+
+func NewRPCClient(opts RPCOptions) *RPCClient {
+	client := resty.New().
+		SetTimeout(opts.Timeout).
+		SetRetryCount(opts.Retries).
+		SetRetryWaitTime(opts.RetryWait).
+		SetRetryMaxWaitTime(opts.RetryMaxWait).
+		SetLogger(quietLogger{}).
+		SetHeader("Content-Type", "application/json").
+		SetHeader("Accept", "application/json").
+		AddRetryCondition(func(resp *resty.Response, err error) bool {
+			if err != nil {
+				return true
+			}
+			return resp.StatusCode() >= http.StatusInternalServerError || resp.StatusCode() == http.StatusTooManyRequests
+		})
+	return &RPCClient{client: client}
+}
+
+var defaultRPCClient = NewRPCClient(DefaultRPCOptions)
+
+// SetRPCOptions replaces the client used by FetchRPC and friends
+func SetRPCOptions(opts RPCOptions) {
+	defaultRPCClient = NewRPCClient(opts)
+}
+
+// DefaultRPCClient is the client used by FetchRPC and friends
+func DefaultRPCClient() *RPCClient {
+	return defaultRPCClient
+}
+
+// Call makes a single JSON-RPC request and returns its result. A JSON-RPC error
+// in the response is returned as an *RPCError.
+func (c *RPCClient) Call(url string, method string, params any) (json.RawMessage, error) {
+	req, err := c.newRequest(RPCRequest{Method: method, Params: params})
+	if err != nil {
+		return nil, err
+	}
+	res := rpcResponse{}
+	if err := c.post(url, req, &res); err != nil {
+		return nil, fmt.Errorf("%s: %w", method, err)
+	}
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return res.Result, nil
+}
+
+// Batch sends all reqs in one HTTP request. Responses are in the same order as
+// reqs, and each one carries its own result or error.
+func (c *RPCClient) Batch(url string, reqs []RPCRequest) ([]RPCResponse, error) {
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+	body := make([]*rpcRequest, len(reqs))
+	for i, r := range reqs {
+		req, err := c.newRequest(r)
+		if err != nil {
+			return nil, err
+		}
+		body[i] = req
+	}
+	res := []rpcResponse{}
+	if err := c.post(url, body, &res); err != nil {
+		return nil, err
+	}
+	if len(res) != len(reqs) {
+		return nil, fmt.Errorf("batch request to %s returned %d responses for %d requests", url, len(res), len(reqs))
+	}
+	// Servers may answer a batch in any order
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	out := make([]RPCResponse, len(res))
+	for i, r := range res {
+		if r.ID != body[i].ID {
+			return nil, fmt.Errorf("batch request to %s is missing a response for %s", url, reqs[i].Method)
+		}
+		out[i] = RPCResponse{Result: r.Result, Error: r.Error}
+	}
+	return out, nil
+}
+
+func (c *RPCClient) newRequest(r RPCRequest) (*rpcRequest, error) {
+	var params []byte
+	switch p := r.Params.(type) {
+	case nil:
+		params = []byte("{}")
+	case string:
+		params = []byte(p)
+	case []byte:
+		params = p
+	default:
+		b, err := json.Marshal(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid params for %s: %w", r.Method, err)
+		}
+		params = b
+	}
+	if len(params) == 0 {
+		params = []byte("{}")
+	}
+	if !json.Valid(params) {
+		return nil, fmt.Errorf("invalid params for %s: %s", r.Method, params)
+	}
+	return &rpcRequest{
+		JSONRPC: "2.0",
+		ID:      c.id.Add(1),
+		Method:  r.Method,
+		Params:  params,
+	}, nil
+}
+
+func (c *RPCClient) post(url string, body any, result any) error {
+	resp, err := c.client.R().SetBody(body).Post(url)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %w", url, err)
+	}
+	if resp.IsError() {
+		return fmt.Errorf("fetch error %d: %s %s", resp.StatusCode(), url, resp.String())
+	}
+	if err := json.Unmarshal(resp.Body(), result); err != nil {
+		return fmt.Errorf("invalid JSON-RPC response from %s: %w", url, err)
+	}
+	return nil
+}
+
+// Fetch GETs url, or POSTs body to it if there is one
+func Fetch(url string, body string) (string, error) {
+	req := defaultRPCClient.client.R()
+	var resp *resty.Response
+	var err error
+	if body == "" {
+		resp, err = req.Get(url)
+	} else {
+		resp, err = req.SetBody(body).Post(url)
+	}
+	if err != nil {
+		return "", err
+	}
+	if resp.IsError() {
+		return "", fmt.Errorf("fetch error %d: %s %s", resp.StatusCode(), url, resp.String())
+	}
+	return resp.String(), nil
+}
+
+// FetchRPC returns the whole JSON-RPC response, params being a JSON string (or ""
+// for none). A JSON-RPC error in the response is returned as an *RPCError.
+func FetchRPC(url string, method string, params string) (string, error) {
+	result, err := defaultRPCClient.Call(url, method, params)
+	if err != nil {
+		return "", err
+	}
+	out, err := json.Marshal(struct {
+		Result json.RawMessage `json:"result"`
+	}{result})
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func FetchRPCGJSON(url string, method string, params string) (*gjson.Result, error) {
+	s, err := FetchRPC(url, method, params)
+	if err != nil {
+		return nil, err
+	}
+	out := gjson.Parse(s)
+	return &out, nil
+}
+
+// quietLogger drops resty's own logging, errors are returned to the caller instead
+type quietLogger struct{}
+
+func (quietLogger) Errorf(format string, v ...interface{}) {}
+func (quietLogger) Warnf(format string, v ...interface{})  {}
+func (quietLogger) Debugf(format string, v ...interface{}) {}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RPCClient(t *testing.T) {
+	var failures atomic.Int32
+	failures.Store(2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failures.Add(-1) >= 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if body[0] == '[' {
+			reqs := []rpcRequest{}
+			require.NoError(t, json.Unmarshal(body, &reqs))
+			// Answer out of order
+			_, _ = w.Write([]byte(`[{"id":` + strconv.FormatUint(reqs[1].ID, 10) + `,"error":{"code":-32000,"message":"nope"}},{"id":` + strconv.FormatUint(reqs[0].ID, 10) + `,"result":"0x1"}]`))
+			return
+		}
+		req := rpcRequest{}
+		require.NoError(t, json.Unmarshal(body, &req))
+		if req.Method == "bad" {
+			_, _ = w.Write([]byte(`{"id":1,"error":{"code":-32601,"message":"method not found"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":1,"result":` + string(req.Params) + `}`))
+	}))
+	defer srv.Close()
+
+	c := NewRPCClient(RPCOptions{Timeout: time.Second, Retries: 3, RetryWait: time.Millisecond, RetryMaxWait: time.Millisecond})
+
+	// Retried past the 503s
+	result, err := c.Call(srv.URL, "echo", map[string]string{"a": "b"})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"a":"b"}`, string(result))
+
+	_, err = c.Call(srv.URL, "bad", nil)
+	rpcErr := &RPCError{}
+	require.True(t, errors.As(err, &rpcErr))
+	require.Equal(t, -32601, rpcErr.Code)
+
+	_, err = c.Call(srv.URL, "echo", `{"a":`)
+	require.ErrorContains(t, err, "invalid params")
+
+	res, err := c.Batch(srv.URL, []RPCRequest{{Method: "eth_chainId"}, {Method: "eth_nope"}})
+	require.NoError(t, err)
+	require.Equal(t, `"0x1"`, string(res[0].Result))
+	require.Equal(t, "nope", res[1].Error.Message)
+}
+
+func Test_Fetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ok" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	out, err := Fetch(srv.URL+"/ok", "")
+	require.NoError(t, err)
+	require.Equal(t, `{"ok":true}`, out)
+	_, err = Fetch(srv.URL+"/missing", `{}`)
+	require.ErrorContains(t, err, "fetch error 404")
+}
@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/lasthyphen/dijetsnode/ids"
+)
+
+// NodeID returns the NodeID of the persistent staking cert in a node's work dir
+func NodeID(workDir string) (ids.NodeID, error) {
+	fn := NewFileLocations(workDir).StakingCertFile
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return ids.EmptyNodeID, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return ids.EmptyNodeID, fmt.Errorf("no certificate found in %s", fn)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return ids.EmptyNodeID, fmt.Errorf("unable to parse %s: %w", fn, err)
+	}
+	return ids.NodeIDFromCert(cert), nil
+}
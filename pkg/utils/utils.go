@@ -9,84 +9,14 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
-	"runtime"
 	"strings"
 	"time"
 
-	"github.com/go-resty/resty/v2"
-	"github.com/hashicorp/go-getter"
 	"github.com/lasthyphen/ecctools/pkg/constants"
 	"github.com/shopspring/decimal"
 	"github.com/tidwall/gjson"
 )
 
-func Fetch(url string, body string) (string, error) {
-	client := resty.New()
-	// client.SetTLSClientConfig(&tls.Config{InsecureSkipVerify: true})
-	client.SetTimeout(30 * time.Second)
-
-	var resp *resty.Response
-	var err error
-
-	if body == "" {
-		resp, err = client.R().
-			EnableTrace().
-			SetHeader("Content-Type", "application/json").
-			SetHeader("Accept", "application/json").
-			Get(url)
-	} else {
-		resp, err = client.R().
-			EnableTrace().
-			SetHeader("Content-Type", "application/json").
-			SetHeader("Accept", "application/json").
-			SetBody(body).
-			Post(url)
-	}
-
-	return resp.String(), err
-}
-
-func FetchRPC(url string, method string, params string) (string, error) {
-	client := resty.New()
-	// client.SetTLSClientConfig(&tls.Config{InsecureSkipVerify: true})
-	client.SetTimeout(30 * time.Second)
-
-	var resp *resty.Response
-	var err error
-
-	if params == "" {
-		params = "{}"
-	}
-
-	body := fmt.Sprintf(`{
-		"jsonrpc": "2.0",
-		"id"     : %d,
-		"method" : "%s",
-		"params" : %s
-	}`, time.Now().Unix(), method, params)
-
-	resp, err = client.R().
-		EnableTrace().
-		SetHeader("Content-Type", "application/json").
-		SetHeader("Accept", "application/json").
-		SetBody(body).
-		Post(url)
-
-	if resp.IsError() {
-		return "", fmt.Errorf("fetch error %d: %s %s", resp.StatusCode(), url, body)
-	}
-	return resp.String(), err
-}
-
-func FetchRPCGJSON(url string, method string, params string) (*gjson.Result, error) {
-	s, err := FetchRPC(url, method, params)
-	if err != nil {
-		return nil, err
-	}
-	out := gjson.Parse(s)
-	return &out, nil
-}
-
 func LinkFile(src, dest string) error {
 	full, err := filepath.Abs(src)
 	if err != nil {
@@ -275,102 +205,6 @@ func ResolveAccountAddrs(accounts *gjson.Result, args []string) []string {
 	return out
 }
 
-func DownloadAvalanchego(destDir string, version string) (url string, destFile string, err error) {
-	goos := runtime.GOOS
-	goarch := runtime.GOARCH
-
-	fn := fmt.Sprintf("dijetsnode-%s", version)
-	destFile = filepath.Join(destDir, fn)
-	if FileExists(destFile) {
-		return url, destFile, fmt.Errorf("file exists: %s", destFile)
-	}
-
-	tdir, err := os.MkdirTemp("", "ggt")
-	if err != nil {
-		return url, destFile, err
-	}
-	defer func() {
-		os.RemoveAll(tdir)
-	}()
-
-	var exeFile string
-	switch goos {
-	case "darwin":
-		url = fmt.Sprintf(
-			"https://github.com/lasthyphen/dijetsnode/releases/download/%s/dijetsnode-macos-%s.zip",
-			version,
-			version,
-		)
-		// It unzips into a 'build' folder
-		exeFile = filepath.Join(tdir, "build", "dijetsnode")
-	case "linux":
-		url = fmt.Sprintf(
-			"https://github.com/lasthyphen/dijetsnode/releases/download/%s/avalanchego-linux-%s-%s.tar.gz",
-			version,
-			goarch,
-			version,
-		)
-		exeFile = filepath.Join(tdir, fmt.Sprintf("dijetsnode-%s", version), "dijetsnode")
-	default:
-		return url, destFile, fmt.Errorf("downloading not supported on OS: %s", goos)
-	}
-
-	err = getter.GetAny(tdir, url)
-	if err != nil {
-		return url, destFile, err
-	}
-
-	err = CopyFile(exeFile, destFile)
-
-	return url, destFile, err
-}
-
-func DownloadSubnetevm(destDir string, version string) (url string, destFile string, err error) {
-	goarch := runtime.GOARCH
-	goos := runtime.GOOS
-	switch goos {
-	case "darwin":
-		url = fmt.Sprintf(
-			"https://github.com/lasthyphen/subnet-evm/releases/download/%s/subnet-evm_%s_darwin_%s.tar.gz",
-			version,
-			version[1:],
-			goarch,
-		)
-	case "linux":
-		url = fmt.Sprintf(
-			"https://github.com/ava-labs/subnet-evm/releases/download/%s/subnet-evm_%s_linux_%s.tar.gz",
-			version,
-			version[1:],
-			goarch,
-		)
-	default:
-		return "", "", fmt.Errorf("downloading not supported on OS: %s", goos)
-	}
-
-	fn := fmt.Sprintf("subnet-evm-%s", version)
-	destFile = filepath.Join(destDir, fn)
-	if FileExists(destFile) {
-		return "", "", fmt.Errorf("file exists: %s", destFile)
-	}
-
-	tdir, err := os.MkdirTemp("", "ggt")
-	if err != nil {
-		return "", "", err
-	}
-	defer func() {
-		os.RemoveAll(tdir)
-	}()
-
-	err = getter.GetAny(tdir, url)
-	if err != nil {
-		return "", "", err
-	}
-
-	err = CopyFile(filepath.Join(tdir, "subnet-evm"), destFile)
-
-	return url, destFile, err
-}
-
 // Take control over where things are placed
 
 type DirectoryLayout struct {
@@ -382,6 +216,7 @@ type DirectoryLayout struct {
 	VMConfigDir     string
 	CChainConfigDir string
 	XChainConfigDir string
+	StakingDir      string
 }
 
 type FileLocations struct {
@@ -392,6 +227,11 @@ type FileLocations struct {
 	VMAliasesFile    string
 	ChainAliasesFile string
 	AvaGenesisFile   string
+	NodeStateFile    string
+	SupervisorLog    string
+	StakingCertFile  string
+	StakingKeyFile   string
+	SignerKeyFile    string
 }
 
 func NewDirectoryLayout(workDir string) DirectoryLayout {
@@ -404,6 +244,7 @@ func NewDirectoryLayout(workDir string) DirectoryLayout {
 		CChainConfigDir: filepath.Join(workDir, "configs", "chains", "C"),
 		XChainConfigDir: filepath.Join(workDir, "configs", "chains", "X"),
 		VMConfigDir:     filepath.Join(workDir, "configs", "vms"),
+		StakingDir:      filepath.Join(workDir, "configs", "staking"),
 	}
 }
 
@@ -416,6 +257,11 @@ func NewFileLocations(workDir string) FileLocations {
 		CChainConfigFile: filepath.Join(workDir, "configs", "chains", "C", "config.json"),
 		XChainConfigFile: filepath.Join(workDir, "configs", "chains", "X", "config.json"),
 		VMAliasesFile:    filepath.Join(workDir, "configs", "vms", "aliases.json"),
+		NodeStateFile:    filepath.Join(workDir, "node-state.json"),
+		SupervisorLog:    filepath.Join(workDir, "ggt.log"),
+		StakingCertFile:  filepath.Join(workDir, "configs", "staking", "staker.crt"),
+		StakingKeyFile:   filepath.Join(workDir, "configs", "staking", "staker.key"),
+		SignerKeyFile:    filepath.Join(workDir, "configs", "staking", "signer.key"),
 	}
 
 }
@@ -0,0 +1,18 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/lasthyphen/dijetsnode/ids"
+)
+
+// VMID is the ID the node expects a VM's plugin to be named after: the name
+// zero-padded to 32 bytes
+func VMID(name string) (ids.ID, error) {
+	if len(name) > 32 {
+		return ids.Empty, fmt.Errorf("VM name must be <= 32 bytes, %s is %d", name, len(name))
+	}
+	paddedBytes := [32]byte{}
+	copy(paddedBytes[:], []byte(name))
+	return ids.ToID(paddedBytes[:])
+}
@@ -0,0 +1,138 @@
+// Package vmbuild compiles VM plugins from source, stamping them with the git
+// commit they were built from.
+package vmbuild
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lasthyphen/ecctools/pkg/utils"
+)
+
+type Options struct {
+	// Root of the VM's Go module
+	Src string
+	// Main package to build, relative to Src (default ./plugin if it exists, like subnet-evm, otherwise .)
+	Pkg string
+	// Where to write the binary
+	Out string
+	// Variable set to the git commit with -X (default <module>/plugin/evm.GitCommit if
+	// that package exists, like subnet-evm, otherwise main.GitCommit)
+	VersionVar string
+	// Extra -ldflags
+	LDFlags string
+	// go build output, defaults to os.Stderr
+	Output io.Writer
+}
+
+type Result struct {
+	Out      string        `json:"out"`
+	Commit   string        `json:"commit"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Build runs go build in opts.Src. The binary is written next to opts.Out
+// and renamed over it, so a plugin the node is running can be replaced.
+func Build(ctx context.Context, opts Options) (*Result, error) {
+	src, err := filepath.Abs(opts.Src)
+	if err != nil {
+		return nil, err
+	}
+	out, err := filepath.Abs(opts.Out)
+	if err != nil {
+		return nil, err
+	}
+	module, err := ModulePath(src)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Pkg == "" {
+		opts.Pkg = "."
+		if utils.DirExists(filepath.Join(src, "plugin")) {
+			opts.Pkg = "./plugin"
+		}
+	}
+	if opts.VersionVar == "" {
+		opts.VersionVar = "main.GitCommit"
+		if utils.DirExists(filepath.Join(src, "plugin", "evm")) {
+			opts.VersionVar = module + "/plugin/evm.GitCommit"
+		}
+	}
+	if opts.Output == nil {
+		opts.Output = os.Stderr
+	}
+
+	r := &Result{Out: out, Commit: GitCommit(src)}
+	ldflags := fmt.Sprintf("-X %s=%s", opts.VersionVar, r.Commit)
+	if opts.LDFlags != "" {
+		ldflags += " " + opts.LDFlags
+	}
+
+	if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+		return nil, err
+	}
+	tmp := out + ".building"
+	start := time.Now()
+	cmd := exec.CommandContext(ctx, "go", "build", "-ldflags", ldflags, "-o", tmp, opts.Pkg)
+	cmd.Dir = src
+	cmd.Stdout = opts.Output
+	cmd.Stderr = opts.Output
+	if err := cmd.Run(); err != nil {
+		_ = os.Remove(tmp)
+		return nil, fmt.Errorf("go build %s in %s failed: %w", opts.Pkg, src, err)
+	}
+	if err := os.Rename(tmp, out); err != nil {
+		return nil, err
+	}
+	r.Duration = time.Since(start)
+	return r, nil
+}
+
+// ModulePath reads the module path from src/go.mod
+func ModulePath(src string) (string, error) {
+	f, err := os.Open(filepath.Join(src, "go.mod"))
+	if err != nil {
+		return "", fmt.Errorf("%s is not a Go module: %w", src, err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if module, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			return strings.Trim(strings.TrimSpace(module), `"`), nil
+		}
+	}
+	return "", fmt.Errorf("no module line in %s/go.mod", src)
+}
+
+// GitCommit is the HEAD of the repo src is in, with -dirty if it has
+// uncommitted changes, or "unknown" if it isn't in a repo.
+func GitCommit(src string) string {
+	out, err := exec.Command("git", "-C", src, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "unknown"
+	}
+	commit := strings.TrimSpace(string(out))
+	if status, err := exec.Command("git", "-C", src, "status", "--porcelain").Output(); err == nil && len(status) > 0 {
+		commit += "-dirty"
+	}
+	return commit
+}
+
+// IsSource is true for files that change what go build produces
+func IsSource(path string) bool {
+	base := filepath.Base(path)
+	return strings.HasSuffix(base, ".go") || base == "go.mod" || base == "go.sum"
+}
+
+// IsIgnoredDir is true for dirs not worth watching for sources, like .git and vendor
+func IsIgnoredDir(path string) bool {
+	base := filepath.Base(path)
+	return base == "vendor" || (strings.HasPrefix(base, ".") && base != "." && base != "..")
+}
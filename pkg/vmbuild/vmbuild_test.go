@@ -0,0 +1,56 @@
+package vmbuild
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(src, "go.mod"), []byte("module example.com/myvm\n\ngo 1.19\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "main.go"), []byte(`package main
+
+import "fmt"
+
+var GitCommit string
+
+func main() { fmt.Print(GitCommit) }
+`), 0644))
+
+	out := filepath.Join(t.TempDir(), "bin", "myvm")
+	r, err := Build(context.Background(), Options{Src: src, Out: out, Output: &bytes.Buffer{}})
+	require.NoError(t, err)
+	require.Equal(t, out, r.Out)
+	require.Equal(t, "unknown", r.Commit)
+
+	got, err := exec.Command(out).Output()
+	require.NoError(t, err)
+	require.Equal(t, "unknown", string(got))
+
+	// A broken build leaves the previous binary in place
+	require.NoError(t, os.WriteFile(filepath.Join(src, "main.go"), []byte("package main\n\nfunc main() {"), 0644))
+	_, err = Build(context.Background(), Options{Src: src, Out: out, Output: &bytes.Buffer{}})
+	require.Error(t, err)
+	require.FileExists(t, out)
+	require.NoFileExists(t, out+".building")
+}
+
+func TestIsSource(t *testing.T) {
+	require.True(t, IsSource("/x/plugin/evm/vm.go"))
+	require.True(t, IsSource("go.sum"))
+	require.False(t, IsSource("/x/README.md"))
+	require.False(t, IsSource("/x/build/myvm"))
+}
+
+func TestIsIgnoredDir(t *testing.T) {
+	require.True(t, IsIgnoredDir("/x/.git"))
+	require.True(t, IsIgnoredDir("/x/vendor"))
+	require.False(t, IsIgnoredDir("/x/plugin"))
+	require.False(t, IsIgnoredDir("."))
+}
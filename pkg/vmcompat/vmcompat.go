@@ -0,0 +1,218 @@
+// Package vmcompat checks that a node binary and its VM plugins speak the same
+// rpcchainvm protocol. The node refuses to load a plugin with a different
+// protocol, and only says so in its logs.
+package vmcompat
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lasthyphen/dijetsnode/version"
+	"github.com/lasthyphen/ecctools/pkg/utils"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	StatusOK           = "ok"
+	StatusIncompatible = "incompatible"
+	// The protocol of the node or the plugin couldn't be determined
+	StatusUnknown = "unknown"
+)
+
+// How long a binary gets to answer --version
+var ProbeTimeout = 10 * time.Second
+
+type Binary struct {
+	// dijetsnode for the node, otherwise the VM's alias (or ID if it has none)
+	Name string `json:"name"`
+	// What the link in the work dir points to
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	// Database version the node reports, empty for plugins
+	Database string `json:"database,omitempty"`
+	// 0 if unknown
+	RPCChainVM uint   `json:"rpcchainvm"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Node    Binary   `json:"node"`
+	Plugins []Binary `json:"plugins"`
+}
+
+var (
+	protocolRe  = regexp.MustCompile(`rpcchainvm=(\d+)`)
+	databaseRe  = regexp.MustCompile(`database=(v?[\d.]+)`)
+	avagoRe     = regexp.MustCompile(`(?i)avalanchego=v?(\d+\.\d+\.\d+)`)
+	semverRe    = regexp.MustCompile(`v?(\d+\.\d+\.\d+)`)
+	firstWordRe = regexp.MustCompile(`^\S+`)
+)
+
+// Check probes the node binary and every plugin linked into workDir
+func Check(workDir string) (*Report, error) {
+	files := utils.NewFileLocations(workDir)
+	dirs := utils.NewDirectoryLayout(workDir)
+
+	aliases := map[string]string{}
+	if b, err := os.ReadFile(files.VMAliasesFile); err == nil {
+		gjson.ParseBytes(b).ForEach(func(vmID, names gjson.Result) bool {
+			if name := names.Get("0").String(); name != "" {
+				aliases[vmID.String()] = name
+			}
+			return true
+		})
+	}
+
+	plugins := map[string]string{}
+	entries, err := os.ReadDir(dirs.PluginDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if alias, ok := aliases[name]; ok {
+			name = alias
+		}
+		plugins[name] = filepath.Join(dirs.PluginDir, e.Name())
+	}
+	return CheckBinaries(files.AvaBinFile, plugins), nil
+}
+
+// CheckBinaries probes nodeBin and plugins (name => path) without needing a work dir
+func CheckBinaries(nodeBin string, plugins map[string]string) *Report {
+	r := &Report{Node: Probe("dijetsnode", nodeBin, true), Plugins: []Binary{}}
+	names := make([]string, 0, len(plugins))
+	for name := range plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := Probe(name, plugins[name], false)
+		switch {
+		case p.RPCChainVM == 0 || r.Node.RPCChainVM == 0:
+			p.Status = StatusUnknown
+		case p.RPCChainVM != r.Node.RPCChainVM:
+			p.Status = StatusIncompatible
+		default:
+			p.Status = StatusOK
+		}
+		r.Plugins = append(r.Plugins, p)
+	}
+	return r
+}
+
+// Probe runs path --version and parses the version and rpcchainvm protocol it reports
+func Probe(name string, path string, isNode bool) Binary {
+	b := Binary{Name: name, Path: path, Status: StatusUnknown}
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		b.Path = target
+	} else {
+		b.Error = err.Error()
+		return b
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ProbeTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, b.Path, "--version").CombinedOutput()
+	if err != nil {
+		b.Error = fmt.Sprintf("%s --version failed: %s", b.Path, err)
+		return b
+	}
+	b.Version, b.RPCChainVM = ParseVersion(string(out), isNode)
+	if m := databaseRe.FindStringSubmatch(string(out)); m != nil && isNode {
+		b.Database = m[1]
+	}
+	if b.RPCChainVM == 0 {
+		b.Error = fmt.Sprintf("unable to find the rpcchainvm protocol in %q", strings.TrimSpace(string(out)))
+	} else if isNode {
+		b.Status = StatusOK
+	}
+	return b
+}
+
+// ParseVersion parses --version output like
+//
+//	avalanche/1.9.8 [database=v1.4.5, rpcchainvm=22, commit=...]
+//	Subnet-EVM/v0.4.8 [AvalancheGo=v1.9.7, rpcchainvm=22]
+//
+// Binaries that predate printing rpcchainvm are looked up by the avalanchego
+// version they report (or are built against, for plugins).
+func ParseVersion(out string, isNode bool) (string, uint) {
+	out = strings.TrimSpace(out)
+	name := firstWordRe.FindString(out)
+	if m := protocolRe.FindStringSubmatch(out); m != nil {
+		protocol, err := strconv.ParseUint(m[1], 10, 32)
+		if err == nil {
+			return name, uint(protocol)
+		}
+	}
+
+	avagoVersion := ""
+	if m := avagoRe.FindStringSubmatch(out); m != nil {
+		avagoVersion = m[1]
+	} else if m := semverRe.FindStringSubmatch(name); m != nil && isNode {
+		avagoVersion = m[1]
+	}
+	return name, protocolFor(avagoVersion)
+}
+
+// protocolFor is the rpcchainvm protocol of an avalanchego version, 0 if it isn't known
+func protocolFor(avagoVersion string) uint {
+	if avagoVersion == "" {
+		return 0
+	}
+	for protocol, versions := range version.RPCChainVMProtocolCompatibility {
+		for _, v := range versions {
+			if strings.TrimPrefix(v.String(), "v") == avagoVersion {
+				return protocol
+			}
+		}
+	}
+	return 0
+}
+
+// Errors are the plugins the node will refuse to load
+func (r *Report) Errors() []string {
+	out := []string{}
+	for _, p := range r.Plugins {
+		if p.Status == StatusIncompatible {
+			out = append(out, fmt.Sprintf("%s (%s) speaks rpcchainvm=%d but %s (%s) speaks rpcchainvm=%d, the node will not load it",
+				p.Name, p.Path, p.RPCChainVM, r.Node.Name, r.Node.Path, r.Node.RPCChainVM))
+		}
+	}
+	return out
+}
+
+// Warnings are binaries whose protocol couldn't be determined
+func (r *Report) Warnings() []string {
+	out := []string{}
+	for _, b := range append([]Binary{r.Node}, r.Plugins...) {
+		if b.Error != "" {
+			out = append(out, fmt.Sprintf("%s: %s", b.Name, b.Error))
+		}
+	}
+	return out
+}
+
+// Table renders the report one binary per line, for humans
+func (r *Report) Table() string {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "%-20s %-24s %-11s %-13s %s\n", "NAME", "VERSION", "RPCCHAINVM", "STATUS", "PATH")
+	for _, b := range append([]Binary{r.Node}, r.Plugins...) {
+		protocol := "?"
+		if b.RPCChainVM != 0 {
+			protocol = strconv.FormatUint(uint64(b.RPCChainVM), 10)
+		}
+		fmt.Fprintf(sb, "%-20s %-24s %-11s %-13s %s\n", b.Name, b.Version, protocol, b.Status, b.Path)
+	}
+	return sb.String()
+}
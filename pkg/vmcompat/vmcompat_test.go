@@ -0,0 +1,59 @@
+package vmcompat
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		out      string
+		isNode   bool
+		name     string
+		protocol uint
+	}{
+		{"avalanche/1.9.8 [database=v1.4.5, rpcchainvm=22, commit=abc]\n", true, "avalanche/1.9.8", 22},
+		{"Subnet-EVM/v0.4.8 [AvalancheGo=v1.9.7, rpcchainvm=22]", false, "Subnet-EVM/v0.4.8", 22},
+		{"avalanche/1.9.3 [database=v1.4.5, commit=abc]", true, "avalanche/1.9.3", 19},
+		{"Subnet-EVM/v0.4.3 [AvalancheGo=v1.9.3]", false, "Subnet-EVM/v0.4.3", 19},
+		// A plugin's own version says nothing about its protocol
+		{"Subnet-EVM/v0.4.3", false, "Subnet-EVM/v0.4.3", 0},
+		{"something else", true, "something", 0},
+	}
+	for _, tt := range tests {
+		name, protocol := ParseVersion(tt.out, tt.isNode)
+		require.Equal(t, tt.name, name, tt.out)
+		require.Equal(t, tt.protocol, protocol, tt.out)
+	}
+}
+
+func fakeBin(t *testing.T, dir string, name string, out string) string {
+	fn := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(fn, []byte("#!/bin/sh\necho '"+out+"'\n"), 0755))
+	return fn
+}
+
+func TestCheckBinaries(t *testing.T) {
+	dir := t.TempDir()
+	node := fakeBin(t, dir, "node", "avalanche/1.9.8 [database=v1.4.5, rpcchainvm=22]")
+	r := CheckBinaries(node, map[string]string{
+		"good":    fakeBin(t, dir, "good", "Subnet-EVM/v0.4.8 [AvalancheGo=v1.9.7, rpcchainvm=22]"),
+		"old":     fakeBin(t, dir, "old", "Subnet-EVM/v0.4.3 [AvalancheGo=v1.9.3, rpcchainvm=19]"),
+		"unknown": fakeBin(t, dir, "unknown", "MyVM"),
+		"missing": filepath.Join(dir, "missing"),
+	})
+
+	require.Equal(t, StatusOK, r.Node.Status)
+	require.Equal(t, uint(22), r.Node.RPCChainVM)
+	statuses := map[string]string{}
+	for _, p := range r.Plugins {
+		statuses[p.Name] = p.Status
+	}
+	require.Equal(t, map[string]string{"good": StatusOK, "old": StatusIncompatible, "unknown": StatusUnknown, "missing": StatusUnknown}, statuses)
+	require.Len(t, r.Errors(), 1)
+	require.Contains(t, r.Errors()[0], "old")
+	require.Len(t, r.Warnings(), 2)
+}